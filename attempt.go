@@ -0,0 +1,123 @@
+package restyoops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yyle88/must"
+	"github.com/yyle88/restyoops/internal/utils"
+)
+
+// Attempt records the outcome of one try in a retry sequence
+// Attempt 记录重试序列中单次尝试的结果
+type Attempt struct {
+	Kind       Kind          // Classification // 分类
+	StatusCode int           // HTTP status code // HTTP 状态码
+	Cause      error         // Wrapped outcome // 被包装的结果
+	WaitTime   time.Duration // Wait time before next try // 下次尝试前的等待时间
+	Duration   time.Duration // Time spent in this try // 本次尝试耗时
+	Timestamp  time.Time     // When this try finished // 本次尝试结束时间
+}
+
+// NewAttempt creates an Attempt from the Oops of one try
+// NewAttempt 根据单次尝试的 Oops 创建 Attempt
+func NewAttempt(oops *Oops, duration time.Duration) *Attempt {
+	must.Full(oops)
+	return &Attempt{
+		Kind:       oops.Kind,
+		StatusCode: oops.StatusCode,
+		Cause:      oops.Cause,
+		WaitTime:   oops.WaitTime,
+		Duration:   duration,
+		Timestamp:  time.Now(),
+	}
+}
+
+// Label returns a short label of the attempt, like "timeout" or "503"
+// Label 返回尝试的简短标签，如 "timeout" 或 "503"
+func (a *Attempt) Label() string {
+	if a.StatusCode > 0 {
+		return strconv.Itoa(a.StatusCode)
+	}
+	if isTimeout(a.Cause) {
+		return "timeout"
+	}
+	return strings.ToLower(a.Kind.String())
+}
+
+// WithAttempts appends attempts to the history and returns the Oops
+// WithAttempts 将尝试追加到历史记录并返回 Oops
+func (o *Oops) WithAttempts(attempts ...*Attempt) *Oops {
+	o.Attempts = append(o.Attempts, attempts...)
+	return o
+}
+
+// Summary describes the attempt history, like "3 attempts: timeout, 503, 503"
+// When no history is recorded, the Oops itself counts as the single attempt
+//
+// Summary 描述尝试历史，如 "3 attempts: timeout, 503, 503"
+// 没有历史记录时，Oops 本身算作唯一的一次尝试
+func (o *Oops) Summary() string {
+	attempts := o.history()
+	labels := make([]string, 0, len(attempts))
+	for _, attempt := range attempts {
+		labels = append(labels, attempt.Label())
+	}
+	if len(attempts) == 1 {
+		return fmt.Sprintf("1 attempt: %s", labels[0])
+	}
+	return fmt.Sprintf("%d attempts: %s", len(attempts), strings.Join(labels, ", "))
+}
+
+// Causes returns the causes of all attempts in sequence
+// Causes 按顺序返回所有尝试的原因
+func (o *Oops) Causes() []error {
+	attempts := o.history()
+	causes := make([]error, 0, len(attempts))
+	for _, attempt := range attempts {
+		if attempt.Cause != nil {
+			causes = append(causes, attempt.Cause)
+		}
+	}
+	return causes
+}
+
+// JoinCauses joins the causes of all attempts, matching errors.Is/As against each of them
+// JoinCauses 合并所有尝试的原因，errors.Is/As 可匹配其中任意一个
+func (o *Oops) JoinCauses() error {
+	return errors.Join(o.Causes()...)
+}
+
+// history returns the recorded attempts, or the Oops itself when none recorded
+// history 返回已记录的尝试，没有记录时返回 Oops 本身
+func (o *Oops) history() []*Attempt {
+	if len(o.Attempts) > 0 {
+		return o.Attempts
+	}
+	return []*Attempt{{
+		Kind:       o.Kind,
+		StatusCode: o.StatusCode,
+		Cause:      o.Cause,
+		WaitTime:   o.WaitTime,
+	}}
+}
+
+// isTimeout checks if the cause indicates a timeout
+// isTimeout 检查原因是否表示超时
+func isTimeout(cause error) bool {
+	if cause == nil {
+		return false
+	}
+	if errors.Is(cause, context.DeadlineExceeded) {
+		return true
+	}
+	if netErr, ok := utils.ErrorsAs[net.Error](cause); ok {
+		return netErr.Timeout()
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package restyoops_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestOops_Summary tests Summary lists each attempt label in sequence
+// TestOops_Summary 测试 Summary 按顺序列出每次尝试的标签
+func TestOops_Summary(t *testing.T) {
+	cfg := restyoops.NewConfig()
+
+	timeout := restyoops.Detect(cfg, nil, context.DeadlineExceeded)
+	unavailable := restyoops.NewOops(restyoops.KindHttp, http.StatusServiceUnavailable, errors.New("503"), true)
+
+	oops := restyoops.NewOops(restyoops.KindHttp, http.StatusServiceUnavailable, errors.New("503"), true)
+	oops.WithAttempts(
+		restyoops.NewAttempt(timeout, time.Second),
+		restyoops.NewAttempt(unavailable, 10*time.Millisecond),
+		restyoops.NewAttempt(oops, 10*time.Millisecond),
+	)
+	require.Equal(t, "3 attempts: timeout, 503, 503", oops.Summary())
+	require.Len(t, oops.Attempts, 3)
+	require.Equal(t, time.Second, oops.Attempts[0].Duration)
+	require.False(t, oops.Attempts[0].Timestamp.IsZero())
+}
+
+// TestOops_Summary_NoHistory tests Summary treats the Oops itself as the single attempt
+// TestOops_Summary_NoHistory 测试 Summary 将 Oops 本身视为唯一的一次尝试
+func TestOops_Summary_NoHistory(t *testing.T) {
+	oops := restyoops.NewOops(restyoops.KindBlock, 0, errors.New("captcha"), false)
+	require.Equal(t, "1 attempt: block", oops.Summary())
+	require.Len(t, oops.Causes(), 1)
+}
+
+// TestOops_JoinCauses tests JoinCauses keeps each attempt cause reachable via errors.Is
+// TestOops_JoinCauses 测试 JoinCauses 保留每次尝试的原因，可通过 errors.Is 匹配
+func TestOops_JoinCauses(t *testing.T) {
+	cfg := restyoops.NewConfig()
+	causeB := errors.New("bad gateway")
+
+	first := restyoops.Detect(cfg, nil, context.DeadlineExceeded)
+	final := restyoops.NewOops(restyoops.KindHttp, http.StatusBadGateway, causeB, true)
+	final.WithAttempts(restyoops.NewAttempt(first, 0), restyoops.NewAttempt(final, 0))
+
+	joined := final.JoinCauses()
+	require.ErrorIs(t, joined, context.DeadlineExceeded)
+	require.ErrorIs(t, joined, causeB)
+	require.Len(t, final.Causes(), 2)
+}
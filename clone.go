@@ -0,0 +1,160 @@
+package restyoops
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the Config, the copy is not frozen
+// Clone 返回 Config 的深拷贝，拷贝不处于冻结状态
+func (c *Config) Clone() *Config {
+	res := *c
+	res.StatusOptions = make(map[int]*StatusOption, len(c.StatusOptions))
+	for statusCode, opt := range c.StatusOptions {
+		if opt != nil { // nil entries are invalid, see Validate
+			res.StatusOptions[statusCode] = &StatusOption{Retryable: opt.Retryable, WaitTime: opt.WaitTime}
+		}
+	}
+	res.KindOptions = make(map[Kind]*KindOption, len(c.KindOptions))
+	for kind, opt := range c.KindOptions {
+		if opt != nil {
+			res.KindOptions[kind] = &KindOption{Retryable: opt.Retryable, WaitTime: opt.WaitTime}
+		}
+	}
+	res.ContentChecks = make(map[int]ContentCheckFunc, len(c.ContentChecks))
+	for statusCode, check := range c.ContentChecks {
+		res.ContentChecks[statusCode] = check
+	}
+	res.HeaderChecks = make(map[int]HeaderCheckFunc, len(c.HeaderChecks))
+	for statusCode, check := range c.HeaderChecks {
+		res.HeaderChecks[statusCode] = check
+	}
+	res.LogLevels = make(map[Kind]slog.Level, len(c.LogLevels))
+	for kind, level := range c.LogLevels {
+		res.LogLevels[kind] = level
+	}
+	res.KindSeverities = make(map[Kind]Severity, len(c.KindSeverities))
+	maps.Copy(res.KindSeverities, c.KindSeverities)
+	res.StatusSeverities = make(map[int]Severity, len(c.StatusSeverities))
+	maps.Copy(res.StatusSeverities, c.StatusSeverities)
+	res.ReasonSeverities = make(map[string]Severity, len(c.ReasonSeverities))
+	maps.Copy(res.ReasonSeverities, c.ReasonSeverities)
+	res.BusinessCodeSeverities = make(map[string]Severity, len(c.BusinessCodeSeverities))
+	maps.Copy(res.BusinessCodeSeverities, c.BusinessCodeSeverities)
+	res.RetryDirectiveHeaders = slices.Clone(c.RetryDirectiveHeaders)
+	res.RequestIDHeaders = slices.Clone(c.RequestIDHeaders)
+	res.RedactQueryKeys = slices.Clone(c.RedactQueryKeys)
+	res.RedactFields = slices.Clone(c.RedactFields)
+	res.frozen = false
+	return &res
+}
+
+// NewOverride creates an empty Config holding only the settings set on it, for use with Merge
+// Unlike NewConfig it has no defaults, so merging it never resets settings of the base
+//
+// NewOverride 创建一个只包含显式设置的空 Config，用于 Merge
+// 与 NewConfig 不同，它没有默认值，因此合并时不会重置原配置的设置
+func NewOverride() *Config {
+	return &Config{
+		StatusOptions: make(map[int]*StatusOption),
+		KindOptions:   make(map[Kind]*KindOption),
+		ContentChecks: make(map[int]ContentCheckFunc),
+		HeaderChecks:  make(map[int]HeaderCheckFunc),
+		LogLevels:     make(map[Kind]slog.Level),
+
+		KindSeverities:         make(map[Kind]Severity),
+		StatusSeverities:       make(map[int]Severity),
+		ReasonSeverities:       make(map[string]Severity),
+		BusinessCodeSeverities: make(map[string]Severity),
+	}
+}
+
+// Merge returns a new Config with the settings of other laid over this one
+// Map entries of other replace entries with the same key, other entries are kept
+// Scalars and lists of other replace these ones only when set with With* methods, defaults of NewConfig are not set ones
+// So other can be built with NewConfig or NewOverride, and zero values like WithDefaultWait(0) apply too
+//
+// Merge 返回一个新的 Config，将 other 的设置覆盖在当前设置之上
+// other 的映射条目替换相同键的条目，其余条目保留
+// other 的标量和列表仅在通过 With* 方法设置时替换当前值，NewConfig 的默认值不算设置
+// 因此 other 可以通过 NewConfig 或 NewOverride 构建，WithDefaultWait(0) 等零值同样生效
+func (c *Config) Merge(other *Config) *Config {
+	res := c.Clone()
+	for statusCode, opt := range other.StatusOptions {
+		if opt != nil {
+			res.StatusOptions[statusCode] = &StatusOption{Retryable: opt.Retryable, WaitTime: opt.WaitTime}
+		}
+	}
+	for kind, opt := range other.KindOptions {
+		if opt != nil {
+			res.KindOptions[kind] = &KindOption{Retryable: opt.Retryable, WaitTime: opt.WaitTime}
+		}
+	}
+	for statusCode, check := range other.ContentChecks {
+		res.ContentChecks[statusCode] = check
+	}
+	for statusCode, check := range other.HeaderChecks {
+		res.HeaderChecks[statusCode] = check
+	}
+	for kind, level := range other.LogLevels {
+		res.LogLevels[kind] = level
+	}
+	maps.Copy(res.KindSeverities, other.KindSeverities)
+	maps.Copy(res.StatusSeverities, other.StatusSeverities)
+	maps.Copy(res.ReasonSeverities, other.ReasonSeverities)
+	maps.Copy(res.BusinessCodeSeverities, other.BusinessCodeSeverities)
+	if other.isSet(settingDefaultWait) {
+		res.DefaultWait = other.DefaultWait
+	}
+	if other.isSet(settingSnippetLimit) {
+		res.SnippetLimit = other.SnippetLimit
+	}
+	if other.isSet(settingBodyLimit) {
+		res.BodyLimit = other.BodyLimit
+	}
+	if other.isSet(settingDefaultLogLevel) {
+		res.DefaultLogLevel = other.DefaultLogLevel
+	}
+	if other.isSet(settingRetryDirectiveHeaders) {
+		res.RetryDirectiveHeaders = slices.Clone(other.RetryDirectiveHeaders)
+	}
+	if other.isSet(settingRequestIDHeaders) {
+		res.RequestIDHeaders = slices.Clone(other.RequestIDHeaders)
+	}
+	if other.isSet(settingRedactQueryKeys) {
+		res.RedactQueryKeys = slices.Clone(other.RedactQueryKeys)
+	}
+	if other.isSet(settingRedactFields) {
+		res.RedactFields = slices.Clone(other.RedactFields)
+	}
+	res.explicit |= other.explicit
+	return res
+}
+
+// Freeze makes the Config immutable and returns it, so it is safe in concurrent Detect calls
+// Once frozen, With* methods return a modified copy and leave this Config untouched
+// Exported maps and lists must not be written directly once frozen
+//
+// Freeze 使 Config 不可变并返回它，从而可安全用于并发的 Detect 调用
+// 冻结后，With* 方法返回修改后的拷贝，当前 Config 保持不变
+// 冻结后不得直接写入导出的映射和列表
+func (c *Config) Freeze() *Config {
+	c.frozen = true
+	return c
+}
+
+// IsFrozen checks if the Config is frozen
+// IsFrozen 检查 Config 是否已冻结
+func (c *Config) IsFrozen() bool {
+	return c.frozen
+}
+
+// mutable returns the Config itself, or a copy when frozen
+// mutable 返回 Config 本身，冻结时返回拷贝
+func (c *Config) mutable() *Config {
+	if c.frozen {
+		return c.Clone()
+	}
+	return c
+}
@@ -0,0 +1,142 @@
+package restyoops_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestConfig_Clone tests changes on the clone leave the base untouched
+// TestConfig_Clone 测试修改拷贝不影响原配置
+func TestConfig_Clone(t *testing.T) {
+	base := restyoops.NewConfig().WithStatusRetryable(500, true, time.Second)
+	clone := base.Clone().
+		WithStatusRetryable(500, false, 0).
+		WithRequestIDHeaders("X-Trace-Id")
+	clone.StatusOptions[500].WaitTime = time.Minute
+
+	require.True(t, base.StatusOptions[500].Retryable)
+	require.Equal(t, time.Second, base.StatusOptions[500].WaitTime)
+	require.Equal(t, []string{"X-Request-Id", "X-Correlation-Id"}, base.RequestIDHeaders)
+	require.False(t, clone.StatusOptions[500].Retryable)
+}
+
+// TestConfig_Merge tests entries of other override the base while the rest is kept
+// TestConfig_Merge 测试 other 的条目覆盖原配置，其余保持不变
+func TestConfig_Merge(t *testing.T) {
+	base := restyoops.NewConfig().
+		WithStatusRetryable(500, true, time.Second).
+		WithStatusRetryable(503, true, time.Second).
+		WithKindRetryable(restyoops.KindNetwork, true, 0)
+	payment := restyoops.NewOverride().
+		WithStatusRetryable(500, false, 0).
+		WithDefaultWait(3 * time.Second)
+
+	merged := base.Merge(payment)
+	require.False(t, merged.StatusOptions[500].Retryable)
+	require.True(t, merged.StatusOptions[503].Retryable)
+	require.True(t, merged.KindOptions[restyoops.KindNetwork].Retryable)
+	require.Equal(t, 3*time.Second, merged.DefaultWait)
+	require.Equal(t, base.RequestIDHeaders, merged.RequestIDHeaders) // unset in other
+	require.True(t, base.StatusOptions[500].Retryable)               // base untouched
+}
+
+// TestConfig_Merge_ZeroValues tests an override sets zero values explicitly while unset ones are kept
+// TestConfig_Merge_ZeroValues 测试覆盖配置可显式设置零值，未设置的值保持不变
+func TestConfig_Merge_ZeroValues(t *testing.T) {
+	base := restyoops.NewConfig().
+		WithBodySnippet(256).
+		WithRetryDirectiveHeaders("X-Should-Retry")
+
+	merged := base.Merge(restyoops.NewOverride().
+		WithDefaultWait(0).
+		WithBodySnippet(0).
+		WithDefaultLogLevel(slog.LevelInfo).
+		WithRetryDirectiveHeaders())
+	require.Equal(t, time.Duration(0), merged.DefaultWait)
+	require.Equal(t, 0, merged.SnippetLimit)
+	require.Equal(t, slog.LevelInfo, merged.DefaultLogLevel)
+	require.Empty(t, merged.RetryDirectiveHeaders)
+	require.Equal(t, base.RequestIDHeaders, merged.RequestIDHeaders)
+
+	kept := base.Merge(restyoops.NewOverride())
+	require.Equal(t, time.Second, kept.DefaultWait)
+	require.Equal(t, 256, kept.SnippetLimit)
+	require.Equal(t, slog.LevelWarn, kept.DefaultLogLevel)
+	require.Equal(t, []string{"X-Should-Retry"}, kept.RetryDirectiveHeaders)
+
+	// Settings set on the merged Config carry over to later merges
+	// 合并结果上的设置在之后的合并中保持
+	again := restyoops.NewConfig().WithBodySnippet(64).Merge(merged)
+	require.Equal(t, 0, again.SnippetLimit)
+}
+
+// TestConfig_Freeze tests With* on a frozen Config returns a modified copy
+// TestConfig_Freeze 测试冻结的 Config 上调用 With* 返回修改后的拷贝
+func TestConfig_Freeze(t *testing.T) {
+	base := restyoops.NewConfig().WithStatusRetryable(500, true, 0).Freeze()
+	require.True(t, base.IsFrozen())
+
+	variant := base.WithStatusRetryable(500, false, 0)
+	require.NotSame(t, base, variant)
+	require.False(t, variant.IsFrozen())
+	require.True(t, base.StatusOptions[500].Retryable)
+	require.False(t, variant.StatusOptions[500].Retryable)
+
+	// Unfrozen configs keep the chained builder behaviour
+	// 未冻结的配置保持链式构建的行为
+	builder := restyoops.NewConfig()
+	require.Same(t, builder, builder.WithDefaultWait(time.Minute))
+}
+
+// TestConfig_Freeze_Concurrent tests deriving variants from a frozen base is race free
+// TestConfig_Freeze_Concurrent 测试从冻结的配置派生变体没有竞态
+func TestConfig_Freeze_Concurrent(t *testing.T) {
+	base := restyoops.NewConfig().Freeze()
+	detective := restyoops.NewDetective(base)
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < 8; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			variant := base.WithStatusRetryable(400+idx, true, 0)
+			require.Contains(t, variant.StatusOptions, 400+idx)
+			_, oops := detective.Detect(nil, context.Canceled)
+			require.NotNil(t, oops)
+		}()
+	}
+	wg.Wait()
+	require.Empty(t, base.StatusOptions)
+}
+
+// TestConfig_Merge_Defaults tests defaults of an override built with NewConfig never reset settings of the base
+// TestConfig_Merge_Defaults 测试通过 NewConfig 构建的覆盖配置的默认值不会重置原配置的设置
+func TestConfig_Merge_Defaults(t *testing.T) {
+	base := restyoops.NewConfig().
+		WithDefaultWait(5 * time.Second).
+		WithRedactFields("ssn")
+
+	merged := base.Merge(restyoops.NewConfig().WithStatusRetryable(500, false, 0))
+	require.Equal(t, 5*time.Second, merged.DefaultWait)
+	require.Equal(t, []string{"ssn"}, merged.RedactFields)
+	require.False(t, merged.StatusOptions[500].Retryable)
+}
+
+// TestConfig_Clone_NilOptions tests nil option entries are skipped instead of panicking
+// TestConfig_Clone_NilOptions 测试 nil 选项条目会被跳过而不会 panic
+func TestConfig_Clone_NilOptions(t *testing.T) {
+	cfg := restyoops.NewConfig()
+	cfg.StatusOptions[500] = nil
+	cfg.KindOptions[restyoops.KindNetwork] = nil
+
+	clone := cfg.Clone()
+	require.NotContains(t, clone.StatusOptions, 500)
+	require.NotContains(t, clone.KindOptions, restyoops.KindNetwork)
+	require.NotContains(t, restyoops.NewConfig().Merge(cfg).StatusOptions, 500)
+}
@@ -0,0 +1,18 @@
+// Command oopscheck runs the oopscheck analyzer, standalone or through go vet
+//
+// Usage:
+//
+//	oopscheck ./...
+//	go vet -vettool=$(which oopscheck) ./...
+//
+// oopscheck 运行 oopscheck 分析器，可单独运行或通过 go vet 运行
+package main
+
+import (
+	"github.com/yyle88/restyoops/oopscheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(oopscheck.Analyzer)
+}
@@ -0,0 +1,123 @@
+// Command oopsrecord records real responses into fixture files, and writes golden files of their classification
+//
+// Usage:
+//
+//	oopsrecord -dir testdata/upstream [-config restyoops.yaml] [-X POST] [-H "Accept: application/json"] [-d body] URL...
+//
+// oopsrecord 将真实响应录制为 fixture 文件，并写入其分类结果的 golden 文件
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/oopsfixture"
+)
+
+// headerFlags collects repeated -H flags
+// headerFlags 收集重复的 -H 参数
+type headerFlags []string
+
+// String joins the headers, implementing flag.Value
+// String 合并请求头，实现 flag.Value
+func (h *headerFlags) String() string { return strings.Join(*h, ", ") }
+
+// Set appends a header, implementing flag.Value
+// Set 追加一个请求头，实现 flag.Value
+func (h *headerFlags) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("header %q is not like \"Key: Value\"", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "oopsrecord:", err)
+		os.Exit(1)
+	}
+}
+
+// run records each URL and writes the missing golden files
+// run 录制每个 URL 并写入缺失的 golden 文件
+func run(args []string) error {
+	flags := flag.NewFlagSet("oopsrecord", flag.ContinueOnError)
+	dir := flags.String("dir", "", "directory of fixture files (required)")
+	configPath := flags.String("config", "", "config file classifying golden files, default config when empty")
+	method := flags.String("X", http.MethodGet, "request method")
+	data := flags.String("d", "", "request body")
+	timeout := flags.Duration("timeout", 30*time.Second, "request timeout")
+	var headers headerFlags
+	flags.Var(&headers, "H", "request header like \"Key: Value\", repeatable")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("-dir and at least one URL are required")
+	}
+
+	cfg := restyoops.NewConfig()
+	if *configPath != "" {
+		loaded, err := restyoops.LoadConfigFile(*configPath)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+
+	recorder := oopsfixture.NewRecorder(*dir).WithConfig(cfg)
+	client := &http.Client{Transport: recorder, Timeout: *timeout}
+	for _, rawURL := range flags.Args() {
+		req, err := http.NewRequest(*method, rawURL, strings.NewReader(*data))
+		if err != nil {
+			return err
+		}
+		for _, header := range headers {
+			key, value, _ := strings.Cut(header, ":")
+			req.Header.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			fmt.Printf("%s %s: %v\n", *method, rawURL, err)
+			continue
+		}
+		_ = resp.Body.Close()
+		fmt.Printf("%s %s: %d\n", *method, rawURL, resp.StatusCode)
+	}
+	if errs := recorder.Errs(); len(errs) > 0 {
+		return errs[0]
+	}
+	return writeGoldens(cfg, *dir)
+}
+
+// writeGoldens writes the golden files of fixtures that have none yet
+// writeGoldens 为尚无 golden 文件的 fixture 写入 golden 文件
+func writeGoldens(cfg *restyoops.Config, dir string) error {
+	fixtures, err := oopsfixture.LoadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fixture := range fixtures {
+		if _, err := oopsfixture.LoadGolden(dir, fixture.Name); err == nil {
+			continue
+		}
+		oops, err := fixture.Detect(cfg)
+		if err != nil {
+			return err
+		}
+		if err := oopsfixture.SaveGolden(dir, fixture.Name, oopsfixture.NewGolden(oops)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/restyoops"
+)
+
+// readDump reads the responses of a raw HTTP dump, like the output of "curl -i" or "curl -i -L"
+// Bodies are bounded by Content-Length, the last response takes the rest of the file
+//
+// readDump 读取原始 HTTP 转储中的响应，如 "curl -i" 或 "curl -i -L" 的输出
+// 响应体以 Content-Length 为界，最后一个响应读取文件剩余内容
+func readDump(path string, data []byte) ([]*entry, error) {
+	reader := bufio.NewReader(bytes.NewReader(data))
+	var entries []*entry
+	for {
+		line, err := readStatusLine(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		source := fmt.Sprintf("%s#%d", path, len(entries)+1)
+		statusCode, err := parseStatusLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		mimeHeader, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: invalid headers: %w", source, err)
+		}
+		header := http.Header(mimeHeader)
+		if header == nil {
+			header = http.Header{}
+		}
+
+		var content []byte
+		switch length, lengthErr := strconv.Atoi(header.Get("Content-Length")); {
+		case statusCode < 200 || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified:
+		case lengthErr == nil && length >= 0:
+			content = make([]byte, length)
+			n, _ := io.ReadFull(reader, content)
+			content = content[:n]
+		default:
+			if content, err = io.ReadAll(reader); err != nil {
+				return nil, fmt.Errorf("%s: %w", source, err)
+			}
+		}
+		exchange := &restyoops.Exchange{StatusCode: statusCode, Header: header, Body: content}
+		entries = append(entries, &entry{Source: source, Exchange: exchange})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s: no HTTP response found", path)
+	}
+	return entries, nil
+}
+
+// readStatusLine skips blank lines and returns the next status line, io.EOF when none is left
+// readStatusLine 跳过空行并返回下一个状态行，没有剩余内容时返回 io.EOF
+func readStatusLine(reader *bufio.Reader) (string, error) {
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// parseStatusLine returns the status code of a line like "HTTP/1.1 503 Service Unavailable" or "HTTP/2 429"
+// parseStatusLine 返回类似 "HTTP/1.1 503 Service Unavailable" 或 "HTTP/2 429" 的状态行中的状态码
+func parseStatusLine(line string) (int, error) {
+	proto, rest, _ := strings.Cut(line, " ")
+	if !strings.HasPrefix(proto, "HTTP/") {
+		return 0, fmt.Errorf("invalid status line %q", line)
+	}
+	code, _, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	statusCode, err := strconv.Atoi(code)
+	if err != nil || statusCode < 100 || statusCode > 999 {
+		return 0, fmt.Errorf("invalid status code in %q", line)
+	}
+	return statusCode, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yyle88/restyoops"
+)
+
+// entry is one exchange read from an input file, Cause is set when the request failed without a response
+// entry 是从输入文件读取的一次交换，请求失败且没有响应时设置 Cause
+type entry struct {
+	Source   string
+	Method   string
+	URL      string
+	Exchange *restyoops.Exchange
+	Cause    error
+}
+
+// harFile is the subset of the HAR 1.2 format read by the classifier
+// harFile 是分类器读取的 HAR 1.2 格式子集
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// harEntry is one request and response of a HAR log
+// harEntry 是 HAR 日志中的一次请求和响应
+type harEntry struct {
+	Time    float64 `json:"time"`
+	Request struct {
+		Method  string      `json:"method"`
+		URL     string      `json:"url"`
+		Headers []harHeader `json:"headers"`
+	} `json:"request"`
+	Response struct {
+		Status  int         `json:"status"`
+		Headers []harHeader `json:"headers"`
+		Content struct {
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+		Error string `json:"_error"`
+	} `json:"response"`
+	Error string `json:"_error"`
+}
+
+// harHeader is one name and value pair of a HAR header list
+// harHeader 是 HAR 头列表中的一个名称和值
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// readHAR reads the entries of a HAR file, entries with status 0 become transport failures
+// readHAR 读取 HAR 文件中的条目，状态为 0 的条目视为传输失败
+func readHAR(path string, data []byte) ([]*entry, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("%s: invalid HAR: %w", path, err)
+	}
+	entries := make([]*entry, 0, len(har.Log.Entries))
+	for idx, item := range har.Log.Entries {
+		exchange := &restyoops.Exchange{
+			StatusCode:    item.Response.Status,
+			Header:        harHeaders(item.Response.Headers),
+			Method:        item.Request.Method,
+			RequestHeader: harHeaders(item.Request.Headers),
+			Duration:      time.Duration(item.Time * float64(time.Millisecond)),
+		}
+		if u, err := url.Parse(item.Request.URL); err == nil {
+			exchange.URL = u
+		}
+		source := fmt.Sprintf("%s#%d", path, idx+1)
+		if item.Response.Status == 0 {
+			message := item.Response.Error
+			if message == "" {
+				message = item.Error
+			}
+			entries = append(entries, &entry{Source: source, Method: item.Request.Method, URL: item.Request.URL, Cause: harCause(message, exchange.URL)})
+			continue
+		}
+		exchange.Body = []byte(item.Response.Content.Text)
+		if item.Response.Content.Encoding == "base64" {
+			content, err := base64.StdEncoding.DecodeString(item.Response.Content.Text)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid base64 content: %w", source, err)
+			}
+			exchange.Body = content
+		}
+		entries = append(entries, &entry{Source: source, Method: item.Request.Method, URL: item.Request.URL, Exchange: exchange})
+	}
+	return entries, nil
+}
+
+// harHeaders converts a HAR header list into an http.Header
+// harHeaders 将 HAR 头列表转换为 http.Header
+func harHeaders(headers []harHeader) http.Header {
+	header := http.Header{}
+	for _, item := range headers {
+		// HTTP/2 pseudo headers like ":status" are not real headers
+		// 类似 ":status" 的 HTTP/2 伪头不是真正的头
+		if strings.HasPrefix(item.Name, ":") {
+			continue
+		}
+		header.Add(item.Name, item.Value)
+	}
+	return header
+}
+
+// harCause rebuilds a transport error from the browser error name, so Detect gives it a network reason
+// Unknown names like "net::ERR_FAILED" stay plain errors
+//
+// harCause 根据浏览器错误名称重建传输错误，使 Detect 给出网络原因
+// 未知名称（如 "net::ERR_FAILED"）保持为普通错误
+func harCause(message string, u *url.URL) error {
+	if message == "" {
+		message = "no response"
+	}
+	var host string
+	if u != nil {
+		host = u.Hostname()
+	}
+	name := strings.TrimPrefix(message, "net::")
+	switch {
+	case name == "ERR_NAME_NOT_RESOLVED":
+		return &net.DNSError{Err: message, Name: host, IsNotFound: true}
+	case strings.HasPrefix(name, "ERR_NAME_") || strings.HasPrefix(name, "ERR_DNS_"):
+		return &net.DNSError{Err: message, Name: host}
+	case name == "ERR_TIMED_OUT" || name == "ERR_CONNECTION_TIMED_OUT":
+		return fmt.Errorf("%s: %w", message, os.ErrDeadlineExceeded)
+	case strings.HasPrefix(name, "ERR_CONNECTION_") || name == "ERR_EMPTY_RESPONSE" || name == "ERR_ADDRESS_UNREACHABLE":
+		return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New(message)}
+	case name == "ERR_ABORTED":
+		return fmt.Errorf("%s: %w", message, context.Canceled)
+	default:
+		return errors.New(message)
+	}
+}
@@ -0,0 +1,206 @@
+// Command restyoops classifies the responses of HAR exports and raw HTTP dumps, to debug incidents offline
+//
+// Usage:
+//
+//	restyoops [-config restyoops.yaml] [-format table|json] [-kind BLOCK,HTTP] [-explain] FILE...
+//
+// Files ending with .har or starting with "{" are read as HAR, others as raw HTTP response dumps like "curl -i" output
+//
+// restyoops 对 HAR 导出文件和原始 HTTP 转储中的响应进行分类，用于离线排查故障
+// 以 .har 结尾或以 "{" 开头的文件按 HAR 读取，其它文件按原始 HTTP 响应转储读取，如 "curl -i" 的输出
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/yyle88/restyoops"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "restyoops:", err)
+		os.Exit(1)
+	}
+}
+
+// result is the classification of one entry, printed as a table row or a JSON object
+// result 是一个条目的分类结果，输出为表格行或 JSON 对象
+type result struct {
+	Source    string   `json:"source"`
+	Method    string   `json:"method,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Status    int      `json:"status,omitempty"`
+	Success   bool     `json:"success"`
+	Kind      string   `json:"kind,omitempty"`
+	Retryable bool     `json:"retryable"`
+	Severity  string   `json:"severity,omitempty"`
+	Wait      string   `json:"wait,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+	Rule      string   `json:"rule"`
+	Error     string   `json:"error,omitempty"`
+	Steps     []string `json:"steps,omitempty"`
+}
+
+// run classifies the entries of each file and prints the results
+// run 对每个文件中的条目进行分类并输出结果
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("restyoops", flag.ContinueOnError)
+	configPath := flags.String("config", "", "config file to classify with, default config when empty")
+	format := flags.String("format", "table", "output format, table or json")
+	kindList := flags.String("kind", "", "comma separated kinds to show like BLOCK,HTTP, all when empty")
+	explain := flags.Bool("explain", false, "include the evaluated rules of each entry")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("at least one file is required")
+	}
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q, expect table or json", *format)
+	}
+	kinds, err := parseKinds(*kindList)
+	if err != nil {
+		return err
+	}
+
+	cfg := restyoops.NewConfig()
+	if *configPath != "" {
+		if cfg, err = restyoops.LoadConfigFile(*configPath); err != nil {
+			return err
+		}
+	}
+
+	results := make([]*result, 0)
+	for _, path := range flags.Args() {
+		entries, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		for _, item := range entries {
+			res := classify(cfg, item, *explain)
+			if len(kinds) > 0 && !slices.Contains(kinds, restyoops.Kind(res.Kind)) {
+				continue
+			}
+			results = append(results, res)
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	return writeTable(stdout, results)
+}
+
+// readFile reads the entries of a HAR file or a raw HTTP dump
+// readFile 读取 HAR 文件或原始 HTTP 转储中的条目
+func readFile(path string) ([]*entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".har") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return readHAR(path, data)
+	}
+	return readDump(path, data)
+}
+
+// classify runs the entry through DetectExchangeExplain
+// classify 通过 DetectExchangeExplain 对条目进行分类
+func classify(cfg *restyoops.Config, item *entry, explain bool) *result {
+	oops, explanation := restyoops.DetectExchangeExplain(cfg, item.Exchange, item.Cause)
+	res := &result{Source: item.Source, Method: item.Method, URL: item.URL, Rule: string(explanation.Decision)}
+	if oops == nil {
+		res.Success = true
+		res.Status = item.Exchange.StatusCode
+	} else {
+		res.Status = oops.StatusCode
+		res.Kind = oops.Kind.String()
+		res.Retryable = oops.Retryable
+		res.Severity = oops.Severity.String()
+		res.Wait = oops.WaitTime.String()
+		res.Reason = oops.Reason
+		if item.Cause != nil {
+			res.Error = item.Cause.Error()
+		}
+	}
+	if explain {
+		for _, step := range explanation.Steps {
+			mark := "-"
+			if step.Matched {
+				mark = "+"
+			}
+			res.Steps = append(res.Steps, fmt.Sprintf("%s %s: %s", mark, step.Rule, step.Detail))
+		}
+	}
+	return res
+}
+
+// writeTable prints the results as aligned columns, followed by the evaluated rules of each entry when explained
+// writeTable 将结果输出为对齐的列，带解释时随后输出每个条目评估的规则
+func writeTable(stdout io.Writer, results []*result) error {
+	writer := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ENTRY\tMETHOD\tURL\tSTATUS\tKIND\tRETRYABLE\tWAIT\tSEVERITY\tRULE\tREASON")
+	for _, res := range results {
+		status, kind, retryable, wait := "-", "OK", "-", "-"
+		if res.Status != 0 {
+			status = strconv.Itoa(res.Status)
+		}
+		if !res.Success {
+			kind, retryable, wait = res.Kind, strconv.FormatBool(res.Retryable), res.Wait
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			res.Source, orDash(res.Method), orDash(res.URL), status, kind, retryable, wait, orDash(res.Severity), res.Rule, orDash(res.Reason))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	for _, res := range results {
+		if len(res.Steps) == 0 {
+			continue
+		}
+		fmt.Fprintf(stdout, "\n%s\n", res.Source)
+		for _, step := range res.Steps {
+			fmt.Fprintf(stdout, "  %s\n", step)
+		}
+	}
+	return nil
+}
+
+// parseKinds parses the comma separated kinds of the -kind flag
+// parseKinds 解析 -kind 参数中逗号分隔的 Kind
+func parseKinds(list string) ([]restyoops.Kind, error) {
+	var kinds []restyoops.Kind
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		kind := restyoops.Kind(strings.ToUpper(name))
+		if !kind.IsRegistered() {
+			return nil, fmt.Errorf("unknown kind %q", name)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// orDash returns "-" for empty table cells
+// orDash 对空的表格单元格返回 "-"
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunJSON tests classifying a HAR file and a curl dump with a config file, filtered by kind
+// TestRunJSON 测试使用配置文件对 HAR 文件和 curl 转储进行分类，并按 Kind 过滤
+func TestRunJSON(t *testing.T) {
+	var stdout bytes.Buffer
+	args := []string{"-config", "testdata/restyoops.yaml", "-format", "json", "-kind", "block,http", "testdata/incident.har", "testdata/curl.txt"}
+	require.NoError(t, run(args, &stdout))
+
+	var results []*result
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+	require.Len(t, results, 3)
+
+	require.Equal(t, "testdata/incident.har#2", results[0].Source)
+	require.Equal(t, "POST", results[0].Method)
+	require.Equal(t, 503, results[0].Status)
+	require.True(t, results[0].Retryable)
+
+	require.Equal(t, "BLOCK", results[1].Kind)
+	require.Equal(t, "error", results[1].Severity)
+	require.Equal(t, "waf", results[1].Reason)
+	require.Equal(t, "content_check", results[1].Rule)
+
+	require.Equal(t, "testdata/curl.txt#2", results[2].Source)
+	require.Equal(t, 429, results[2].Status)
+	require.Equal(t, "7s", results[2].Wait)
+	require.Equal(t, "status_option", results[2].Rule)
+}
+
+// TestRunTable tests the table output, browser failures get network reasons
+// TestRunTable 测试表格输出，浏览器失败获得网络原因
+func TestRunTable(t *testing.T) {
+	var stdout bytes.Buffer
+	require.NoError(t, run([]string{"-kind", "network", "-explain", "testdata/incident.har"}, &stdout))
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.True(t, strings.HasPrefix(lines[0], "ENTRY"))
+	require.Contains(t, lines[1], "dns_not_found")
+	require.Contains(t, lines[1], "https://missing.example.com/")
+	require.Contains(t, lines[2], "connection")
+	require.Contains(t, stdout.String(), "+ network: NETWORK dns_not_found")
+}
+
+// TestRunInvalid tests invalid flags and files are rejected
+// TestRunInvalid 测试无效的参数和文件会被拒绝
+func TestRunInvalid(t *testing.T) {
+	var stdout bytes.Buffer
+	require.ErrorContains(t, run([]string{"-kind", "oops", "testdata/curl.txt"}, &stdout), "unknown kind")
+	require.ErrorContains(t, run([]string{"-format", "xml", "testdata/curl.txt"}, &stdout), "unknown format")
+	require.ErrorContains(t, run([]string{"testdata/restyoops.yaml"}, &stdout), "invalid status line")
+}
+
+// TestReadDump tests responses of a dump are split by Content-Length
+// TestReadDump 测试转储中的响应按 Content-Length 分割
+func TestReadDump(t *testing.T) {
+	entries, err := readDump("dump", []byte("HTTP/1.1 100 Continue\r\n\r\nHTTP/1.1 502 Bad Gateway\r\nContent-Length: 4\r\n\r\noops\r\n"))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 100, entries[0].Exchange.StatusCode)
+	require.Equal(t, 502, entries[1].Exchange.StatusCode)
+	require.Equal(t, "oops", string(entries[1].Exchange.Body))
+}
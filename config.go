@@ -1,6 +1,12 @@
 package restyoops
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
 
 // StatusOption holds retryable and wait time settings
 // StatusOption 保存可重试和等待时间设置
@@ -20,6 +26,10 @@ type KindOption struct {
 // ContentCheckFunc 检查内容，匹配时返回 Oops，否则返回 nil
 type ContentCheckFunc func(contentType string, content []byte) *Oops
 
+// HeaderCheckFunc checks headers and content, returns Oops if matched, nil otherwise
+// HeaderCheckFunc 检查响应头和内容，匹配时返回 Oops，否则返回 nil
+type HeaderCheckFunc func(header http.Header, content []byte) *Oops
+
 // Config holds customizable detection settings
 // Config 保存可自定义的检测设置
 type Config struct {
@@ -27,6 +37,52 @@ type Config struct {
 	KindOptions   map[Kind]*KindOption
 	DefaultWait   time.Duration            // default wait time // 默认等待时间
 	ContentChecks map[int]ContentCheckFunc // custom content checks // 自定义内容检查
+	HeaderChecks  map[int]HeaderCheckFunc  // custom header checks // 自定义响应头检查
+
+	RetryDirectiveHeaders []string // boolean headers telling whether to retry, none by default // 指示是否重试的布尔响应头，默认没有
+
+	RequestIDHeaders []string // request-ID headers to capture // 需要捕获的请求 ID 头
+	RedactQueryKeys  []string // query keys with secret values // 值需要脱敏的查询参数名
+
+	SnippetLimit int      // max bytes of body snippet, 0 disables // 响应体摘录最大字节数，0 表示关闭
+	RedactFields []string // JSON fields with secret values // 值需要脱敏的 JSON 字段
+	BodyLimit    int      // max bytes of net/http body read for checks and snippet // 为检查和摘录读取的 net/http 响应体最大字节数
+
+	LogLevels       map[Kind]slog.Level // log level per Kind // 各 Kind 的日志级别
+	DefaultLogLevel slog.Level          // log level when Kind not set // Kind 未设置时的日志级别
+
+	KindSeverities         map[Kind]Severity   // severity per Kind // 各 Kind 的严重程度
+	StatusSeverities       map[int]Severity    // severity per status code // 各状态码的严重程度
+	ReasonSeverities       map[string]Severity // severity per reason // 各原因的严重程度
+	BusinessCodeSeverities map[string]Severity // severity per business code // 各业务码的严重程度
+
+	explicit setting // scalars and lists set with With* methods // 通过 With* 方法设置的标量和列表
+	frozen   bool    // With* methods copy before writing once frozen // 冻结后 With* 方法先复制再写入
+}
+
+// DefaultBodyLimit is the max bytes of net/http body read when BodyLimit is not positive
+// DefaultBodyLimit 是 BodyLimit 非正数时读取 net/http 响应体的最大字节数
+const DefaultBodyLimit = 1 << 20
+
+// setting flags a scalar or list of Config, so Merge can tell zero values set on purpose from unset ones
+// setting 标记 Config 的标量或列表，使 Merge 能区分有意设置的零值和未设置的值
+type setting uint
+
+const (
+	settingDefaultWait setting = 1 << iota
+	settingSnippetLimit
+	settingDefaultLogLevel
+	settingRetryDirectiveHeaders
+	settingRequestIDHeaders
+	settingRedactQueryKeys
+	settingRedactFields
+	settingBodyLimit
+)
+
+// isSet checks if the setting was set with a With* method
+// isSet 检查该设置是否通过 With* 方法设置
+func (c *Config) isSet(flag setting) bool {
+	return c.explicit&flag != 0
 }
 
 // NewConfig creates a Config with sensible defaults
@@ -37,12 +93,31 @@ func NewConfig() *Config {
 		KindOptions:   make(map[Kind]*KindOption),
 		DefaultWait:   time.Second, // 1s default
 		ContentChecks: make(map[int]ContentCheckFunc),
+		HeaderChecks:  make(map[int]HeaderCheckFunc),
+
+		RetryDirectiveHeaders: nil, // opt-in, see WithRetryDirectiveHeaders
+
+		RequestIDHeaders: []string{"X-Request-Id", "X-Correlation-Id"},
+		RedactQueryKeys:  []string{"access_token", "api_key", "apikey", "key", "password", "secret", "sign", "signature", "token"},
+
+		SnippetLimit: 0,
+		RedactFields: []string{"access_token", "api_key", "password", "refresh_token", "secret", "token"},
+		BodyLimit:    DefaultBodyLimit,
+
+		LogLevels:       make(map[Kind]slog.Level),
+		DefaultLogLevel: slog.LevelWarn,
+
+		KindSeverities:         make(map[Kind]Severity),
+		StatusSeverities:       make(map[int]Severity),
+		ReasonSeverities:       make(map[string]Severity),
+		BusinessCodeSeverities: make(map[string]Severity),
 	}
 }
 
 // WithStatusRetryable sets retryable and wait time based on status code
 // WithStatusRetryable 基于状态码设置可重试和等待时间
 func (c *Config) WithStatusRetryable(statusCode int, retryable bool, waitTime time.Duration) *Config {
+	c = c.mutable()
 	c.StatusOptions[statusCode] = &StatusOption{
 		Retryable: retryable,
 		WaitTime:  waitTime,
@@ -53,6 +128,7 @@ func (c *Config) WithStatusRetryable(statusCode int, retryable bool, waitTime ti
 // WithKindRetryable sets retryable and wait time based on Kind
 // WithKindRetryable 基于 Kind 设置可重试和等待时间
 func (c *Config) WithKindRetryable(kind Kind, retryable bool, waitTime time.Duration) *Config {
+	c = c.mutable()
 	c.KindOptions[kind] = &KindOption{
 		Retryable: retryable,
 		WaitTime:  waitTime,
@@ -63,6 +139,8 @@ func (c *Config) WithKindRetryable(kind Kind, retryable bool, waitTime time.Dura
 // WithDefaultWait sets the default wait time
 // WithDefaultWait 设置默认等待时间
 func (c *Config) WithDefaultWait(d time.Duration) *Config {
+	c = c.mutable()
+	c.explicit |= settingDefaultWait
 	c.DefaultWait = d
 	return c
 }
@@ -70,6 +148,188 @@ func (c *Config) WithDefaultWait(d time.Duration) *Config {
 // WithContentCheck adds a custom content check
 // WithContentCheck 添加自定义内容检查
 func (c *Config) WithContentCheck(statusCode int, check ContentCheckFunc) *Config {
+	c = c.mutable()
 	c.ContentChecks[statusCode] = check
 	return c
 }
+
+// WithHeaderCheck adds a custom header check, run after the content check of the same status code
+// WithHeaderCheck 添加自定义响应头检查，在相同状态码的内容检查之后运行
+func (c *Config) WithHeaderCheck(statusCode int, check HeaderCheckFunc) *Config {
+	c = c.mutable()
+	c.HeaderChecks[statusCode] = check
+	return c
+}
+
+// WithRetryDirectiveHeaders sets the boolean headers telling whether to retry, checked in sequence
+// The first header holding a boolean overrides the status code decision, StatusOptions included
+// Off by default, no headers turns it off again
+//
+// WithRetryDirectiveHeaders 设置指示是否重试的布尔响应头，按顺序检查
+// 第一个包含布尔值的响应头覆盖按状态码的决定，包括 StatusOptions
+// 默认关闭，不传响应头表示再次关闭
+func (c *Config) WithRetryDirectiveHeaders(headers ...string) *Config {
+	c = c.mutable()
+	c.explicit |= settingRetryDirectiveHeaders
+	c.RetryDirectiveHeaders = headers
+	return c
+}
+
+// WithRequestIDHeaders sets the request-ID headers to capture, checked in sequence
+// WithRequestIDHeaders 设置需要捕获的请求 ID 头，按顺序检查
+func (c *Config) WithRequestIDHeaders(headers ...string) *Config {
+	c = c.mutable()
+	c.explicit |= settingRequestIDHeaders
+	c.RequestIDHeaders = headers
+	return c
+}
+
+// WithRedactQueryKeys sets the query keys whose values are redacted in captured URLs
+// WithRedactQueryKeys 设置在捕获 URL 中需要脱敏的查询参数名
+func (c *Config) WithRedactQueryKeys(keys ...string) *Config {
+	c = c.mutable()
+	c.explicit |= settingRedactQueryKeys
+	c.RedactQueryKeys = keys
+	return c
+}
+
+// WithBodySnippet enables body snippet on Oops, capped at limit bytes
+// WithBodySnippet 启用 Oops 上的响应体摘录，最多 limit 字节
+func (c *Config) WithBodySnippet(limit int) *Config {
+	c = c.mutable()
+	c.explicit |= settingSnippetLimit
+	c.SnippetLimit = limit
+	return c
+}
+
+// WithRedactFields sets the JSON fields whose values are redacted in body snippet
+// WithRedactFields 设置在响应体摘录中需要脱敏的 JSON 字段
+func (c *Config) WithRedactFields(fields ...string) *Config {
+	c = c.mutable()
+	c.explicit |= settingRedactFields
+	c.RedactFields = fields
+	return c
+}
+
+// WithBodyLimit sets the max bytes of net/http body read for checks and snippet, checks see only this part
+// WithBodyLimit 设置为检查和摘录读取的 net/http 响应体最大字节数，检查只能看到这一部分
+func (c *Config) WithBodyLimit(limit int) *Config {
+	c = c.mutable()
+	c.explicit |= settingBodyLimit
+	c.BodyLimit = limit
+	return c
+}
+
+// WithLogLevel sets the log level used when logging issues of the Kind
+// WithLogLevel 设置记录该 Kind 问题时使用的日志级别
+func (c *Config) WithLogLevel(kind Kind, level slog.Level) *Config {
+	c = c.mutable()
+	c.LogLevels[kind] = level
+	return c
+}
+
+// WithDefaultLogLevel sets the log level used when no level is set for the Kind
+// WithDefaultLogLevel 设置 Kind 未设置级别时使用的日志级别
+func (c *Config) WithDefaultLogLevel(level slog.Level) *Config {
+	c = c.mutable()
+	c.explicit |= settingDefaultLogLevel
+	c.DefaultLogLevel = level
+	return c
+}
+
+// WithKindSeverity sets the severity of issues of the Kind
+// WithKindSeverity 设置该 Kind 问题的严重程度
+func (c *Config) WithKindSeverity(kind Kind, severity Severity) *Config {
+	c = c.mutable()
+	c.KindSeverities[kind] = severity
+	return c
+}
+
+// WithStatusSeverity sets the severity of issues with the status code, above the Kind setting
+// WithStatusSeverity 设置该状态码问题的严重程度，优先于 Kind 设置
+func (c *Config) WithStatusSeverity(statusCode int, severity Severity) *Config {
+	c = c.mutable()
+	c.StatusSeverities[statusCode] = severity
+	return c
+}
+
+// WithReasonSeverity sets the severity of issues with the reason, above the status setting
+// WithReasonSeverity 设置该原因问题的严重程度，优先于状态码设置
+func (c *Config) WithReasonSeverity(reason string, severity Severity) *Config {
+	c = c.mutable()
+	c.ReasonSeverities[reason] = severity
+	return c
+}
+
+// WithBusinessCodeSeverity sets the severity of issues with the business code, above all other settings
+// WithBusinessCodeSeverity 设置该业务码问题的严重程度，优先于其它所有设置
+func (c *Config) WithBusinessCodeSeverity(code string, severity Severity) *Config {
+	c = c.mutable()
+	c.BusinessCodeSeverities[code] = severity
+	return c
+}
+
+// Validate checks the Config is complete and its settings are in range
+// Validate 检查 Config 是否完整且设置在有效范围内
+func (c *Config) Validate() error {
+	if c == nil {
+		return errors.New("config is nil")
+	}
+	if c.StatusOptions == nil || c.KindOptions == nil || c.ContentChecks == nil || c.HeaderChecks == nil || c.LogLevels == nil ||
+		c.KindSeverities == nil || c.StatusSeverities == nil || c.ReasonSeverities == nil || c.BusinessCodeSeverities == nil {
+		return errors.New("config maps are nil, create it with NewConfig")
+	}
+
+	var errs []error
+	if c.DefaultWait < 0 {
+		errs = append(errs, fmt.Errorf("negative default wait %v", c.DefaultWait))
+	}
+	if c.BodyLimit < 0 {
+		errs = append(errs, fmt.Errorf("negative body limit %d", c.BodyLimit))
+	}
+	for statusCode, opt := range c.StatusOptions {
+		if !isValidStatusCode(statusCode) {
+			errs = append(errs, fmt.Errorf("invalid status code %d", statusCode))
+		} else if opt == nil || opt.WaitTime < 0 {
+			errs = append(errs, fmt.Errorf("invalid option of status code %d", statusCode))
+		}
+	}
+	for kind, opt := range c.KindOptions {
+		if !kind.IsRegistered() {
+			errs = append(errs, fmt.Errorf("unknown kind %q", kind))
+		} else if opt == nil || opt.WaitTime < 0 {
+			errs = append(errs, fmt.Errorf("invalid option of kind %s", kind))
+		}
+	}
+	for statusCode, check := range c.ContentChecks {
+		if !isValidStatusCode(statusCode) || check == nil {
+			errs = append(errs, fmt.Errorf("invalid content check of status code %d", statusCode))
+		}
+	}
+	for statusCode, check := range c.HeaderChecks {
+		if !isValidStatusCode(statusCode) || check == nil {
+			errs = append(errs, fmt.Errorf("invalid header check of status code %d", statusCode))
+		}
+	}
+	for kind, severity := range c.KindSeverities {
+		if !kind.IsRegistered() || !severity.IsValid() {
+			errs = append(errs, fmt.Errorf("invalid severity %q of kind %q", severity, kind))
+		}
+	}
+	for statusCode, severity := range c.StatusSeverities {
+		if !isValidStatusCode(statusCode) || !severity.IsValid() {
+			errs = append(errs, fmt.Errorf("invalid severity %q of status code %d", severity, statusCode))
+		}
+	}
+	for reason, severity := range c.ReasonSeverities {
+		if reason == "" || !severity.IsValid() {
+			errs = append(errs, fmt.Errorf("invalid severity %q of reason %q", severity, reason))
+		}
+	}
+	for code, severity := range c.BusinessCodeSeverities {
+		if code == "" || !severity.IsValid() {
+			errs = append(errs, fmt.Errorf("invalid severity %q of business code %q", severity, code))
+		}
+	}
+	return errors.Join(errs...)
+}
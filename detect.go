@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -16,72 +18,149 @@ import (
 // Detect classifies a resty response
 // Detect 分类 resty 响应
 func Detect(cfg *Config, resp *resty.Response, respCause error) *Oops {
+	return detect(cfg, newRestyExchange(resp), respCause, nil)
+}
+
+// DetectExchange classifies a transport-agnostic exchange with the same rules as Detect
+// DetectExchange 使用与 Detect 相同的规则分类与传输方式无关的交换
+func DetectExchange(cfg *Config, exchange *Exchange, respCause error) *Oops {
+	return detect(cfg, exchange, respCause, nil)
+}
+
+// detect classifies an exchange and captures the request context, recording rules into explain when not nil
+// detect 分类交换并捕获请求上下文，explain 不为 nil 时记录评估的规则
+func detect(cfg *Config, exchange *Exchange, respCause error, explain *Explanation) *Oops {
+	oops := classify(cfg, exchange, respCause, explain)
+	if oops != nil && oops.Severity == "" {
+		oops.Severity = severityOf(cfg, oops)
+	}
+	if oops != nil && exchange != nil {
+		captureRequest(cfg, oops, exchange)
+		oops.BodySnippet = makeSnippet(cfg, exchange.Header.Get("Content-Type"), exchange.Body)
+	}
+	return oops
+}
+
+// classify classifies an exchange without request context
+// classify 分类交换，不包含请求上下文
+func classify(cfg *Config, exchange *Exchange, respCause error, explain *Explanation) *Oops {
 	if respCause != nil {
-		return detectNetworkOops(cfg, respCause)
+		return detectNetworkOops(cfg, respCause, explain)
 	}
 
-	must.Full(resp)
+	must.Full(exchange)
 
-	statusCode := resp.StatusCode()
-	contentType := resp.Header().Get("Content-Type")
-	content := resp.Body()
+	statusCode := exchange.StatusCode
+	contentType := exchange.Header.Get("Content-Type")
+	content := exchange.Body
 
 	// Run custom content check
 	// 运行自定义内容检查
 	if check, ok := cfg.ContentChecks[statusCode]; ok {
 		if oops := check(contentType, content); oops != nil {
+			explain.step(RuleContentCheck, true, "status %d: %s %s", statusCode, oops.Kind, oops.Cause)
+			waitSource := RuleContentCheck
+			if oops.defaultWait {
+				oops.WaitTime, oops.defaultWait = cfg.DefaultWait, false
+				waitSource = RuleDefaultWait
+			}
+			explain.decide(RuleContentCheck, waitSource)
+			oops.Source = RuleContentCheck
+			return oops
+		}
+		explain.step(RuleContentCheck, false, "status %d: passed", statusCode)
+	}
+
+	// Run custom header check
+	// 运行自定义响应头检查
+	if check, ok := cfg.HeaderChecks[statusCode]; ok {
+		if oops := check(exchange.Header, content); oops != nil {
+			explain.step(RuleHeaderCheck, true, "status %d: %s %s", statusCode, oops.Kind, oops.Cause)
+			waitSource := RuleHeaderCheck
+			if oops.defaultWait {
+				oops.WaitTime, oops.defaultWait = cfg.DefaultWait, false
+				waitSource = RuleDefaultWait
+			}
+			explain.decide(RuleHeaderCheck, waitSource)
+			oops.Source = RuleHeaderCheck
 			return oops
 		}
+		explain.step(RuleHeaderCheck, false, "status %d: passed", statusCode)
 	}
 
 	// Check HTTP status code
 	// 检查 HTTP 状态码
 	if statusCode >= 400 {
-		return detectDefaultHttpOops(cfg, statusCode, contentType)
+		return detectDefaultHttpOops(cfg, statusCode, exchange.Header, explain)
 	}
 
 	// Success - return nil (no oops means no problem)
 	// 成功 - 返回 nil（没有 oops 表示没问题）
+	explain.step(RuleHttpStatus, false, "status %d: success", statusCode)
+	explain.decide(RuleSuccess, RuleSuccess)
 	return nil
 }
 
 // detectNetworkOops classifies network issues
 // detectNetworkOops 分类网络问题
-func detectNetworkOops(cfg *Config, respCause error) *Oops {
+func detectNetworkOops(cfg *Config, respCause error, explain *Explanation) *Oops {
 	var kind Kind
+	var reason string
 	var defaultRetryable bool
 
 	// Check specific types first (more specific before common)
 	// 先检查具体类型（具体的在通用的前面）
-	if errors.Is(respCause, context.DeadlineExceeded) || errors.Is(respCause, context.Canceled) {
+	if errors.Is(respCause, context.DeadlineExceeded) {
 		kind = KindNetwork
+		reason = ReasonTimeout
+		defaultRetryable = true
+	} else if errors.Is(respCause, context.Canceled) {
+		kind = KindNetwork
+		reason = ReasonCanceled
 		defaultRetryable = true
 	} else if dnsErr, ok := utils.ErrorsAs[*net.DNSError](respCause); ok {
 		kind = KindNetwork
+		reason = ReasonDNS
+		if dnsErr.IsNotFound {
+			reason = ReasonDNSNotFound
+		}
 		defaultRetryable = !dnsErr.IsNotFound
 	} else if _, ok := utils.ErrorsAs[*net.OpError](respCause); ok {
 		kind = KindNetwork
+		reason = ReasonConnection
 		defaultRetryable = true
-	} else if _, ok := utils.ErrorsAs[*url.Error](respCause); ok {
+	} else if urlErr, ok := utils.ErrorsAs[*url.Error](respCause); ok {
 		kind = KindNetwork
+		reason = ReasonTransport
+		if urlErr.Timeout() {
+			reason = ReasonTimeout
+		}
 		defaultRetryable = true
 	} else if netErr, ok := utils.ErrorsAs[net.Error](respCause); ok {
 		kind = KindNetwork
+		reason = ReasonNetwork
+		if netErr.Timeout() {
+			reason = ReasonTimeout
+		}
 		defaultRetryable = netErr.Timeout()
 	} else {
 		kind = KindUnknown
+		reason = ReasonUnknown
 		defaultRetryable = false
 	}
+	explain.step(RuleNetwork, kind == KindNetwork, "%s %s: default retryable=%t", kind, reason, defaultRetryable)
 
-	retryable, waitTime := applyOption(cfg, kind, 0, defaultRetryable)
+	retryable, waitTime, source := applyOption(cfg, kind, 0, defaultRetryable, explain)
 	oops := NewOops(kind, 0, respCause, retryable)
 	oops.WithWaitTime(waitTime)
+	oops.WithReason(reason)
+	oops.Source = source
 	return oops
 }
 
 // detectDefaultHttpOops classifies HTTP status code issues
 // detectDefaultHttpOops 分类 HTTP 状态码问题
-func detectDefaultHttpOops(cfg *Config, statusCode int, contentType string) *Oops {
+func detectDefaultHttpOops(cfg *Config, statusCode int, header http.Header, explain *Explanation) *Oops {
 	var defaultRetryable bool
 	switch statusCode {
 	case http.StatusTooManyRequests, http.StatusRequestTimeout: // 429, 408
@@ -97,35 +176,85 @@ func detectDefaultHttpOops(cfg *Config, statusCode int, contentType string) *Oop
 	default:
 		defaultRetryable = statusCode >= 500
 	}
+	explain.step(RuleHttpStatus, true, "status %d: default retryable=%t", statusCode, defaultRetryable)
+
+	retryable, waitTime, source := applyOption(cfg, KindHttp, statusCode, defaultRetryable, explain)
+
+	// Server directive overrides the status code decision
+	// 服务端指示覆盖按状态码的决定
+	if directive, name, ok := findRetryDirective(cfg, header); ok {
+		explain.step(RuleRetryDirective, true, "%s: retryable=%t", name, directive)
+		explain.override(RuleRetryDirective)
+		retryable, source = directive, RuleRetryDirective
+	}
 
-	retryable, waitTime := applyOption(cfg, KindHttp, statusCode, defaultRetryable)
 	oops := NewOops(KindHttp, statusCode, errors.New(string(KindHttp)), retryable)
 	oops.WithWaitTime(waitTime)
-	oops.WithContentType(contentType)
+	oops.WithContentType(header.Get("Content-Type"))
+	oops.WithReason(httpReason(statusCode))
+	oops.Source = source
 	return oops
 }
 
-// applyOption applies config overrides and returns (retryable, waitTime)
-// applyOption 应用配置覆盖并返回 (retryable, waitTime)
-func applyOption(cfg *Config, kind Kind, statusCode int, defaultRetryable bool) (bool, time.Duration) {
+// findRetryDirective returns the first configured header holding a boolean
+// findRetryDirective 返回第一个包含布尔值的已配置响应头
+func findRetryDirective(cfg *Config, header http.Header) (bool, string, bool) {
+	for _, name := range cfg.RetryDirectiveHeaders {
+		if directive, err := strconv.ParseBool(strings.TrimSpace(header.Get(name))); err == nil {
+			return directive, name, true
+		}
+	}
+	return false, "", false
+}
+
+// httpReason converts the status text into a reason, like "too_many_requests"
+// httpReason 将状态文本转换为原因，如 "too_many_requests"
+func httpReason(statusCode int) string {
+	text := http.StatusText(statusCode)
+	if text == "" {
+		return "http_" + strconv.Itoa(statusCode)
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r == ' ' || r == '-':
+			return '_'
+		default:
+			return -1 // drop punctuation like the apostrophe in "I'm a teapot"
+		}
+	}, strings.ToLower(text))
+}
+
+// applyOption applies config overrides and returns (retryable, waitTime, source)
+// applyOption 应用配置覆盖并返回 (retryable, waitTime, source)
+func applyOption(cfg *Config, kind Kind, statusCode int, defaultRetryable bool, explain *Explanation) (bool, time.Duration, Rule) {
 	must.Full(cfg)
 	if statusCode > 0 {
 		if opt, ok := cfg.StatusOptions[statusCode]; ok {
-			waitTime := opt.WaitTime
+			explain.step(RuleStatusOption, true, "status %d: retryable=%t wait=%v", statusCode, opt.Retryable, opt.WaitTime)
+			waitTime, waitSource := opt.WaitTime, RuleStatusOption
 			if waitTime == 0 {
-				waitTime = cfg.DefaultWait
+				waitTime, waitSource = cfg.DefaultWait, RuleDefaultWait
 			}
-			return opt.Retryable, waitTime
+			explain.decide(RuleStatusOption, waitSource)
+			return opt.Retryable, waitTime, RuleStatusOption
 		}
+		explain.step(RuleStatusOption, false, "status %d: not configured", statusCode)
 	}
 
 	if opt, ok := cfg.KindOptions[kind]; ok {
-		waitTime := opt.WaitTime
+		explain.step(RuleKindOption, true, "kind %s: retryable=%t wait=%v", kind, opt.Retryable, opt.WaitTime)
+		waitTime, waitSource := opt.WaitTime, RuleKindOption
 		if waitTime == 0 {
-			waitTime = cfg.DefaultWait
+			waitTime, waitSource = cfg.DefaultWait, RuleDefaultWait
 		}
-		return opt.Retryable, waitTime
+		explain.decide(RuleKindOption, waitSource)
+		return opt.Retryable, waitTime, RuleKindOption
 	}
+	explain.step(RuleKindOption, false, "kind %s: not configured", kind)
 
-	return defaultRetryable, cfg.DefaultWait
+	explain.step(RuleDefault, true, "retryable=%t wait=%v", defaultRetryable, cfg.DefaultWait)
+	explain.decide(RuleDefault, RuleDefaultWait)
+	return defaultRetryable, cfg.DefaultWait, RuleDefault
 }
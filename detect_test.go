@@ -61,6 +61,7 @@ func TestDetect_HTTP429(t *testing.T) {
 	oops := restyoops.Detect(restyoops.NewConfig(), resp, err)
 	require.Equal(t, restyoops.KindHttp, oops.Kind)
 	require.Equal(t, 429, oops.StatusCode)
+	require.Equal(t, "too_many_requests", oops.Reason)
 	require.True(t, oops.Retryable)
 }
 
@@ -86,6 +87,7 @@ func TestDetect_HTTP404(t *testing.T) {
 func TestDetect_NetworkTimeout(t *testing.T) {
 	oops := restyoops.Detect(restyoops.NewConfig(), nil, context.DeadlineExceeded)
 	require.Equal(t, restyoops.KindNetwork, oops.Kind)
+	require.Equal(t, restyoops.ReasonTimeout, oops.Reason)
 	require.True(t, oops.Retryable)
 }
 
@@ -150,3 +152,82 @@ func TestConfig_Override500NotRetryable(t *testing.T) {
 	oops = restyoops.Detect(cfg, resp, err)
 	require.False(t, oops.Retryable)
 }
+
+// TestConfig_HeaderCheck tests a header check classifies the response before the status code defaults
+// TestConfig_HeaderCheck 测试响应头检查在状态码默认值之前分类响应
+func TestConfig_HeaderCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", r.URL.Query().Get("remaining"))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	cfg := restyoops.NewConfig().WithHeaderCheck(403, func(header http.Header, content []byte) *restyoops.Oops {
+		if header.Get("X-RateLimit-Remaining") != "0" {
+			return nil
+		}
+		return restyoops.NewOops(restyoops.KindHttp, 403, errors.New("rate limited"), true).WithWaitTime(time.Minute)
+	})
+
+	client := resty.New()
+	resp, err := client.R().SetQueryParam("remaining", "0").Get(server.URL)
+	require.NoError(t, err)
+	oops, explain := restyoops.DetectExplain(cfg, resp, nil)
+	require.True(t, oops.Retryable)
+	require.Equal(t, time.Minute, oops.WaitTime)
+	require.Equal(t, restyoops.RuleHeaderCheck, explain.Decision)
+
+	// Passed header check falls back to the status code defaults
+	resp, err = client.R().SetQueryParam("remaining", "10").Get(server.URL)
+	require.NoError(t, err)
+	oops = restyoops.Detect(cfg, resp, nil)
+	require.False(t, oops.Retryable)
+}
+
+// TestConfig_RetryDirective tests retry directive headers override the status code decision
+// TestConfig_RetryDirective 测试重试指示头覆盖按状态码的决定
+func TestConfig_RetryDirective(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if value := r.URL.Query().Get("should"); value != "" {
+			w.Header().Set("X-Should-Retry", value)
+		}
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	// Directives are off by default
+	client := resty.New()
+	resp, err := client.R().SetQueryParam("should", "true").Get(server.URL)
+	require.NoError(t, err)
+	oops := restyoops.Detect(restyoops.NewConfig(), resp, nil)
+	require.False(t, oops.Retryable)
+	require.Equal(t, restyoops.RuleDefault, oops.Source)
+
+	directed := restyoops.NewConfig().WithRetryDirectiveHeaders("Stripe-Should-Retry", "X-Should-Retry", "X-Retryable")
+	oops, explain := restyoops.DetectExplain(directed, resp, nil)
+	require.True(t, oops.Retryable)
+	require.Equal(t, restyoops.RuleRetryDirective, oops.Source)
+	require.Equal(t, restyoops.RuleRetryDirective, explain.Decision)
+	require.Equal(t, restyoops.RuleDefaultWait, explain.WaitSource)
+
+	// Directive overrides status options too
+	cfg := directed.WithStatusRetryable(409, true, 0)
+	resp, err = client.R().SetQueryParam("should", "false").Get(server.URL)
+	require.NoError(t, err)
+	oops = restyoops.Detect(cfg, resp, nil)
+	require.False(t, oops.Retryable)
+	require.Equal(t, restyoops.RuleRetryDirective, oops.Source)
+
+	// Without the header, or with directives disabled, the status code decides
+	resp, err = client.R().Get(server.URL)
+	require.NoError(t, err)
+	oops = restyoops.Detect(cfg, resp, nil)
+	require.True(t, oops.Retryable)
+	require.Equal(t, restyoops.RuleStatusOption, oops.Source)
+
+	resp, err = client.R().SetQueryParam("should", "false").Get(server.URL)
+	require.NoError(t, err)
+	oops = restyoops.Detect(cfg.WithRetryDirectiveHeaders(), resp, nil)
+	require.True(t, oops.Retryable)
+	require.Equal(t, restyoops.RuleStatusOption, oops.Source)
+}
@@ -1,28 +1,114 @@
 package restyoops
 
 import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/go-resty/resty/v2"
 	"github.com/yyle88/must"
 )
 
 // Detective wraps Config and provides a convenient API
+// The Config can be swapped at runtime, in-flight Detect calls keep the snapshot they started with
+//
 // Detective 封装 Config 并提供便捷的 API
+// Config 可在运行时替换，进行中的 Detect 调用继续使用开始时的快照
 type Detective struct {
-	cfg *Config
+	cfg         atomic.Pointer[Config]
+	routes      atomic.Pointer[[]*routeEntry]
+	routesMutex sync.Mutex
 }
 
-// NewDetective creates a Detective with the specified Config
-// NewDetective 使用指定的 Config 创建 Detective
+// NewDetective creates a Detective with a frozen copy of the Config, later changes to the Config do not affect it
+// NewDetective 使用 Config 的冻结拷贝创建 Detective，之后对该 Config 的修改不会影响它
 func NewDetective(cfg *Config) *Detective {
-	return &Detective{
-		cfg: must.Full(cfg),
+	c := &Detective{}
+	c.cfg.Store(must.Full(cfg).Clone().Freeze())
+	return c
+}
+
+// Config returns the current frozen default Config snapshot
+// Config 返回当前已冻结的默认 Config 快照
+func (c *Detective) Config() *Config {
+	return c.cfg.Load()
+}
+
+// Swap validates the Config and atomically replaces the current default one with a frozen copy of it
+// Swap 校验 Config，并用它的冻结拷贝原子地替换当前默认 Config
+func (c *Detective) Swap(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	c.cfg.Store(cfg.Clone().Freeze())
+	return nil
+}
+
+// Reload loads a YAML or JSON config and swaps it in, keeping the current one on failure
+// Reload 加载 YAML 或 JSON 配置并替换，失败时保留当前配置
+func (c *Detective) Reload(r io.Reader) error {
+	cfg, err := LoadConfig(r)
+	if err != nil {
+		return err
+	}
+	return c.Swap(cfg)
+}
+
+// ReloadFile loads the config file and swaps it in, keeping the current one on failure
+// ReloadFile 加载配置文件并替换，失败时保留当前配置
+func (c *Detective) ReloadFile(path string) error {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return c.Swap(cfg)
+}
+
+// WatchFile polls the config file and reloads it on modification, until ctx is done
+// Failures are passed to onFailure and the current Config is kept
+//
+// WatchFile 轮询配置文件并在修改时重新加载，直到 ctx 结束
+// 失败会传给 onFailure，并保留当前 Config
+func (c *Detective) WatchFile(ctx context.Context, path string, interval time.Duration, onFailure func(error)) {
+	var lastModTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastModTime = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			onFailure(err)
+			continue
+		}
+		if info.ModTime().Equal(lastModTime) {
+			continue
+		}
+		lastModTime = info.ModTime()
+		if err := c.ReloadFile(path); err != nil {
+			onFailure(err)
+		}
 	}
 }
 
 // Detect classifies a resty response and returns both response and oops issue
+// The Config is selected by the routes matching the request, falling back to the default one
+//
 // Detect 分类 resty 响应并返回响应和 oops 问题
+// 根据匹配请求的路由选择 Config，未匹配时使用默认 Config
 func (c *Detective) Detect(resp *resty.Response, respCause error) (*resty.Response, *OopsIssue) {
-	oops := Detect(c.cfg, resp, respCause)
+	oops := Detect(c.selectResponse(resp), resp, respCause)
 	if oops != nil {
 		must.Nice(oops.Kind)
 		must.Wrong(oops.Cause)
@@ -1,9 +1,16 @@
 package restyoops_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/require"
@@ -44,3 +51,100 @@ func TestDetective_Detect_HTTP500(t *testing.T) {
 	require.NotNil(t, response)
 	require.Equal(t, http.StatusInternalServerError, response.StatusCode())
 }
+
+// TestDetective_Swap tests swapping in a new Config changes later classifications
+// TestDetective_Swap 测试替换 Config 后改变后续的分类结果
+func TestDetective_Swap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	detective := restyoops.NewDetective(restyoops.NewConfig())
+	_, oopsIssue := detective.Detect(resty.New().R().Get(server.URL))
+	require.True(t, oopsIssue.Retryable)
+
+	require.NoError(t, detective.Reload(strings.NewReader("status:\n  - code: 500\n    retryable: false\n")))
+	_, oopsIssue = detective.Detect(resty.New().R().Get(server.URL))
+	require.False(t, oopsIssue.Retryable)
+
+	// Invalid config is rejected, keeping the current one
+	// 无效配置被拒绝，保留当前配置
+	current := detective.Config()
+	require.Error(t, detective.Swap(restyoops.NewConfig().WithStatusRetryable(99, true, 0)))
+	require.Error(t, detective.Reload(strings.NewReader("status:\n  - code: 99\n")))
+	require.Error(t, detective.Swap(&restyoops.Config{}))
+	require.Same(t, current, detective.Config())
+}
+
+// TestDetective_ConfigCopy tests the Detective keeps frozen copies, the Configs passed in stay writable and detached
+// TestDetective_ConfigCopy 测试 Detective 保存冻结的拷贝，传入的 Config 保持可写且互不影响
+func TestDetective_ConfigCopy(t *testing.T) {
+	cfg := restyoops.NewConfig()
+	detective := restyoops.NewDetective(cfg)
+	require.False(t, cfg.IsFrozen())
+	require.True(t, detective.Config().IsFrozen())
+
+	require.Same(t, cfg, cfg.WithStatusRetryable(500, false, 0))
+	require.NotContains(t, detective.Config().StatusOptions, 500)
+
+	swapped := restyoops.NewConfig()
+	require.NoError(t, detective.Swap(swapped))
+	swapped.WithStatusRetryable(500, false, 0)
+	require.False(t, swapped.IsFrozen())
+	require.NotContains(t, detective.Config().StatusOptions, 500)
+
+	routed := restyoops.NewConfig()
+	detective.WithRoute(restyoops.Route{Path: "/pay/"}, routed)
+	routed.WithStatusRetryable(500, false, 0)
+	require.False(t, routed.IsFrozen())
+	require.NotContains(t, detective.SelectURL(http.MethodGet, &url.URL{Path: "/pay/1"}).StatusOptions, 500)
+}
+
+// TestDetective_Swap_Concurrent tests Detect and Swap run concurrently without races
+// TestDetective_Swap_Concurrent 测试 Detect 和 Swap 并发运行没有竞态
+func TestDetective_Swap_Concurrent(t *testing.T) {
+	detective := restyoops.NewDetective(restyoops.NewConfig())
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < 4; idx++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				_, oopsIssue := detective.Detect(nil, context.DeadlineExceeded)
+				require.Equal(t, restyoops.KindNetwork, oopsIssue.Kind)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				cfg := restyoops.NewConfig().WithKindRetryable(restyoops.KindNetwork, false, time.Second)
+				require.NoError(t, detective.Swap(cfg))
+			}
+		}()
+	}
+	wg.Wait()
+	require.False(t, detective.Config().KindOptions[restyoops.KindNetwork].Retryable)
+}
+
+// TestDetective_WatchFile tests the file is reloaded after modification
+// TestDetective_WatchFile 测试文件修改后会重新加载
+func TestDetective_WatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "restyoops.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("default_wait: 1s\n"), 0o644))
+
+	detective := restyoops.NewDetective(restyoops.NewConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go detective.WatchFile(ctx, path, 5*time.Millisecond, func(err error) { t.Log(err) })
+
+	require.NoError(t, os.WriteFile(path, []byte("default_wait: 7s\n"), 0o644))
+	require.Eventually(t, func() bool {
+		// Keep moving the mod time, since the watcher may start after the write
+		// 持续修改文件时间，因为监听可能在写入之后才启动
+		modTime := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+		return detective.Config().DefaultWait == 7*time.Second
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,135 @@
+package restyoops
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Exchange is a transport-agnostic view of one HTTP response and its request, classified by DetectExchange
+// Exchange 是与传输方式无关的 HTTP 响应及其请求的视图，由 DetectExchange 分类
+type Exchange struct {
+	StatusCode int         // HTTP status code // HTTP 状态码
+	Header     http.Header // Response headers // 响应头
+	Body       []byte      // Response body // 响应体
+
+	Method        string        // Request method // 请求方法
+	URL           *url.URL      // Request URL // 请求 URL
+	RequestHeader http.Header   // Request headers // 请求头
+	Duration      time.Duration // Elapsed time of the request // 请求耗时
+	RemoteAddr    string        // Remote address when known // 已知时的远端地址
+}
+
+// newRestyExchange converts a resty response into an Exchange, nil response gives nil
+// newRestyExchange 将 resty 响应转换为 Exchange，响应为 nil 时返回 nil
+func newRestyExchange(resp *resty.Response) *Exchange {
+	if resp == nil {
+		return nil
+	}
+	exchange := &Exchange{
+		StatusCode: resp.StatusCode(),
+		Header:     resp.Header(),
+		Body:       resp.Body(),
+		Duration:   resp.Time(),
+	}
+	if req := resp.Request; req != nil {
+		exchange.Method = req.Method
+		exchange.URL = restyRequestURL(req)
+		exchange.RequestHeader = req.Header
+		if remoteAddr := req.TraceInfo().RemoteAddr; remoteAddr != nil {
+			exchange.RemoteAddr = remoteAddr.String()
+		}
+	}
+	return exchange
+}
+
+// restyRequestURL returns the sent URL of the resty request, falling back to the parsed template URL
+// restyRequestURL 返回 resty 请求实际发送的 URL，缺失时回退到解析后的模板 URL
+func restyRequestURL(req *resty.Request) *url.URL {
+	if req.RawRequest != nil && req.RawRequest.URL != nil {
+		return req.RawRequest.URL
+	}
+	if u, err := url.Parse(req.URL); err == nil {
+		return u
+	}
+	return nil
+}
+
+// newHTTPExchange converts a net/http response into an Exchange, nil response gives nil
+// The body is read only when checks or the snippet need it, capped at the BodyLimit of the Config
+// The read part is put back in front of the rest, so the caller can still read the whole body
+//
+// newHTTPExchange 将 net/http 响应转换为 Exchange，响应为 nil 时返回 nil
+// 仅在检查或摘录需要时读取响应体，最多读取 Config 的 BodyLimit 字节
+// 已读取的部分会放回剩余部分之前，调用方仍可读取完整的响应体
+func newHTTPExchange(cfg *Config, resp *http.Response) (*Exchange, error) {
+	if resp == nil {
+		return nil, nil
+	}
+	exchange := &Exchange{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	if req := resp.Request; req != nil {
+		exchange.Method = req.Method
+		exchange.URL = req.URL
+		exchange.RequestHeader = req.Header
+	}
+	if resp.Body == nil || resp.Body == http.NoBody || !needsBody(cfg, resp.StatusCode) {
+		return exchange, nil
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, bodyLimit(cfg)))
+	exchange.Body = content
+	if err != nil {
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(content), &errorReader{err: err}))
+		return exchange, err
+	}
+	resp.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(content), resp.Body), Closer: resp.Body}
+	return exchange, nil
+}
+
+// needsBody checks if classifying the status code reads the body, through checks or the snippet
+// needsBody 检查分类该状态码时是否通过检查或摘录读取响应体
+func needsBody(cfg *Config, statusCode int) bool {
+	if _, ok := cfg.ContentChecks[statusCode]; ok {
+		return true
+	}
+	if _, ok := cfg.HeaderChecks[statusCode]; ok {
+		return true
+	}
+	return statusCode >= 400 && cfg.SnippetLimit > 0
+}
+
+// bodyLimit returns the max bytes read from the body, falling back to DefaultBodyLimit when not positive
+// bodyLimit 返回读取响应体的最大字节数，非正数时回退到 DefaultBodyLimit
+func bodyLimit(cfg *Config) int64 {
+	if cfg.BodyLimit <= 0 {
+		return DefaultBodyLimit
+	}
+	return int64(cfg.BodyLimit)
+}
+
+// replayBody reads the buffered part and then the rest of the body, closing the original body
+// replayBody 先读取已缓冲的部分再读取剩余的响应体，关闭时关闭原响应体
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errorReader replays the read error after the buffered content
+// errorReader 在缓冲内容之后重放读取错误
+type errorReader struct {
+	err error
+}
+
+// Read returns the read error
+// Read 返回读取错误
+func (r *errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,129 @@
+package restyoops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Rule names a detection rule evaluated in Detect
+// Rule 表示 Detect 中评估的检测规则
+type Rule string
+
+const (
+	// RuleContentCheck is the custom content check registered with the status code
+	// RuleContentCheck 是按状态码注册的自定义内容检查
+	RuleContentCheck Rule = "content_check"
+
+	// RuleHeaderCheck is the custom header check registered with the status code
+	// RuleHeaderCheck 是按状态码注册的自定义响应头检查
+	RuleHeaderCheck Rule = "header_check"
+
+	// RuleRetryDirective is the server directive header telling whether to retry
+	// RuleRetryDirective 是服务端指示是否重试的响应头
+	RuleRetryDirective Rule = "retry_directive"
+
+	// RuleNetwork is the built-in classification of transport causes
+	// RuleNetwork 是传输原因的内置分类
+	RuleNetwork Rule = "network"
+
+	// RuleHttpStatus is the built-in classification of HTTP status codes
+	// RuleHttpStatus 是 HTTP 状态码的内置分类
+	RuleHttpStatus Rule = "http_status"
+
+	// RuleStatusOption is the retryable setting configured with the status code
+	// RuleStatusOption 是按状态码配置的可重试设置
+	RuleStatusOption Rule = "status_option"
+
+	// RuleKindOption is the retryable setting configured with the Kind
+	// RuleKindOption 是按 Kind 配置的可重试设置
+	RuleKindOption Rule = "kind_option"
+
+	// RuleDefault is the built-in default retryable value
+	// RuleDefault 是内置的默认可重试值
+	RuleDefault Rule = "default"
+
+	// RuleDefaultWait is the default wait time of Config
+	// RuleDefaultWait 是 Config 的默认等待时间
+	RuleDefaultWait Rule = "default_wait"
+
+	// RuleSuccess means no rule reported an issue
+	// RuleSuccess 表示没有规则报告问题
+	RuleSuccess Rule = "success"
+)
+
+// Step records one rule evaluated in Detect
+// Step 记录 Detect 中评估的一条规则
+type Step struct {
+	Rule    Rule   // Evaluated rule // 评估的规则
+	Matched bool   // Whether the rule applied // 规则是否生效
+	Detail  string // What the rule saw // 规则看到的内容
+}
+
+// Explanation lists the rules evaluated in Detect and which of them decided the outcome
+// Explanation 列出 Detect 中评估的规则以及决定结果的规则
+type Explanation struct {
+	Steps      []*Step // Evaluated rules in sequence // 按顺序评估的规则
+	Decision   Rule    // Rule deciding Retryable // 决定是否可重试的规则
+	WaitSource Rule    // Rule supplying WaitTime // 提供等待时间的规则
+}
+
+// DetectExplain classifies a resty response like Detect, and explains how the outcome was decided
+// DetectExplain 与 Detect 一样分类 resty 响应，并解释结果是如何决定的
+func DetectExplain(cfg *Config, resp *resty.Response, respCause error) (*Oops, *Explanation) {
+	explain := &Explanation{}
+	oops := detect(cfg, newRestyExchange(resp), respCause, explain)
+	return oops, explain
+}
+
+// DetectExchangeExplain classifies an exchange like DetectExchange, and explains how the outcome was decided
+// DetectExchangeExplain 与 DetectExchange 一样分类交换，并解释结果是如何决定的
+func DetectExchangeExplain(cfg *Config, exchange *Exchange, respCause error) (*Oops, *Explanation) {
+	explain := &Explanation{}
+	oops := detect(cfg, exchange, respCause, explain)
+	return oops, explain
+}
+
+// String renders the explanation as lines, matched rules marked with "+"
+// String 将解释渲染为多行文本，生效的规则用 "+" 标记
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	for _, step := range e.Steps {
+		mark := "-"
+		if step.Matched {
+			mark = "+"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s: %s\n", mark, step.Rule, step.Detail))
+	}
+	sb.WriteString(fmt.Sprintf("decision: %s, wait: %s", e.Decision, e.WaitSource))
+	return sb.String()
+}
+
+// step records an evaluated rule, no-op on nil explanation
+// step 记录一条已评估的规则，解释为 nil 时不做任何事
+func (e *Explanation) step(rule Rule, matched bool, format string, args ...any) {
+	if e == nil {
+		return
+	}
+	e.Steps = append(e.Steps, &Step{Rule: rule, Matched: matched, Detail: fmt.Sprintf(format, args...)})
+}
+
+// override replaces the rule deciding Retryable and keeps the wait source, no-op on nil explanation
+// override 替换决定可重试的规则并保留等待时间来源，解释为 nil 时不做任何事
+func (e *Explanation) override(decision Rule) {
+	if e == nil {
+		return
+	}
+	e.Decision = decision
+}
+
+// decide records the rules deciding Retryable and WaitTime, no-op on nil explanation
+// decide 记录决定可重试和等待时间的规则，解释为 nil 时不做任何事
+func (e *Explanation) decide(decision Rule, waitSource Rule) {
+	if e == nil {
+		return
+	}
+	e.Decision = decision
+	e.WaitSource = waitSource
+}
@@ -0,0 +1,94 @@
+package restyoops_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestDetectExplain_StatusOption tests the status option decides while the default wait applies
+// TestDetectExplain_StatusOption 测试状态码配置决定结果，而等待时间来自默认值
+func TestDetectExplain_StatusOption(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	resp, err := resty.New().R().Get(server.URL)
+
+	cfg := restyoops.NewConfig().
+		WithStatusRetryable(403, true, 0).
+		WithKindRetryable(restyoops.KindHttp, false, 5*time.Second)
+	oops, explain := restyoops.DetectExplain(cfg, resp, err)
+	require.True(t, oops.Retryable)
+	require.Equal(t, time.Second, oops.WaitTime)
+	require.Equal(t, restyoops.RuleStatusOption, explain.Decision)
+	require.Equal(t, restyoops.RuleDefaultWait, explain.WaitSource)
+
+	require.Len(t, explain.Steps, 2)
+	require.Equal(t, restyoops.RuleHttpStatus, explain.Steps[0].Rule)
+	require.Equal(t, restyoops.RuleStatusOption, explain.Steps[1].Rule)
+	require.True(t, explain.Steps[1].Matched)
+	require.Contains(t, explain.String(), "decision: status_option, wait: default_wait")
+}
+
+// TestDetectExplain_ContentCheck tests a matching content check decides the outcome
+// TestDetectExplain_ContentCheck 测试匹配的内容检查决定结果
+func TestDetectExplain_ContentCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("please solve the captcha"))
+	}))
+	defer server.Close()
+
+	resp, err := resty.New().R().Get(server.URL)
+
+	cfg := restyoops.NewConfig().WithContentCheck(200, func(contentType string, content []byte) *restyoops.Oops {
+		if bytes.Contains(content, []byte("captcha")) {
+			return restyoops.NewOops(restyoops.KindBlock, 200, errors.New("captcha"), true)
+		}
+		return nil
+	})
+	oops, explain := restyoops.DetectExplain(cfg, resp, err)
+	require.Equal(t, restyoops.KindBlock, oops.Kind)
+	require.Equal(t, restyoops.RuleContentCheck, explain.Decision)
+	require.Len(t, explain.Steps, 1)
+	require.True(t, explain.Steps[0].Matched)
+}
+
+// TestDetectExplain_Network tests kind option and default steps on network issues
+// TestDetectExplain_Network 测试网络问题时的 Kind 配置和默认值步骤
+func TestDetectExplain_Network(t *testing.T) {
+	oops, explain := restyoops.DetectExplain(restyoops.NewConfig(), nil, context.DeadlineExceeded)
+	require.True(t, oops.Retryable)
+	require.Equal(t, restyoops.RuleDefault, explain.Decision)
+	require.Equal(t, restyoops.RuleDefaultWait, explain.WaitSource)
+
+	rules := make([]restyoops.Rule, 0, len(explain.Steps))
+	for _, step := range explain.Steps {
+		rules = append(rules, step.Rule)
+	}
+	require.Equal(t, []restyoops.Rule{restyoops.RuleNetwork, restyoops.RuleKindOption, restyoops.RuleDefault}, rules)
+}
+
+// TestDetectExplain_Success tests success is explained without an Oops
+// TestDetectExplain_Success 测试成功时没有 Oops 但有解释
+func TestDetectExplain_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := resty.New().R().Get(server.URL)
+	oops, explain := restyoops.DetectExplain(restyoops.NewConfig(), resp, err)
+	require.Nil(t, oops)
+	require.Equal(t, restyoops.RuleSuccess, explain.Decision)
+}
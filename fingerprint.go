@@ -0,0 +1,70 @@
+package restyoops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// idSegment matches path segments holding a digit, candidates of IDs
+// idSegment 匹配包含数字的路径段，作为 ID 的候选
+var idSegment = regexp.MustCompile(`^[A-Za-z0-9_.~-]*[0-9][A-Za-z0-9_.~-]*$`)
+
+// minTokenLength is the shortest segment mixing letters and digits taken as an ID, like a UUID or a hash
+// Shorter names like "v1" or "oauth2" are kept
+//
+// minTokenLength 是被视为 ID 的字母数字混合段的最短长度，如 UUID 或哈希
+// 更短的名称如 "v1"、"oauth2" 会被保留
+const minTokenLength = 16
+
+// Route returns the method and the URL path with IDs replaced by ":id", like "GET /users/:id/orders"
+// Route 返回请求方法和将 ID 替换为 ":id" 的 URL 路径，如 "GET /users/:id/orders"
+func (o *Oops) Route() string {
+	path := ""
+	if u, err := url.Parse(o.URL); err == nil {
+		path = normalizePath(u.EscapedPath())
+	}
+	return strings.TrimSpace(o.Method + " " + path)
+}
+
+// Fingerprint returns a stable hash grouping identical failures across URLs with different IDs
+// It covers Kind, status code, reason, host, normalised route and business code
+//
+// Fingerprint 返回稳定的哈希值，将 ID 不同但本质相同的失败归为一组
+// 它涵盖 Kind、状态码、原因、主机、归一化的路由和业务码
+func (o *Oops) Fingerprint() string {
+	host := ""
+	if u, err := url.Parse(o.URL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+	parts := []string{o.Kind.String(), strconv.Itoa(o.StatusCode), o.Reason, host, o.Route(), o.BusinessCode}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizePath replaces the ID segments of the path with ":id"
+// normalizePath 将路径中的 ID 段替换为 ":id"
+func normalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for idx, segment := range segments {
+		if isIDSegment(segment) {
+			segments[idx] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isIDSegment checks if the path segment holds an ID: all digits, or a long token with digits
+// isIDSegment 检查路径段是否包含 ID：全部为数字，或带数字的长令牌
+func isIDSegment(segment string) bool {
+	if !idSegment.MatchString(segment) {
+		return false
+	}
+	if _, err := strconv.ParseUint(segment, 10, 64); err == nil {
+		return true
+	}
+	return len(segment) >= minTokenLength
+}
@@ -0,0 +1,55 @@
+package restyoops_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// newRequestOops creates an HTTP Oops of the request
+// newRequestOops 创建请求对应的 HTTP Oops
+func newRequestOops(method string, rawURL string, statusCode int) *restyoops.Oops {
+	oops := restyoops.NewOops(restyoops.KindHttp, statusCode, errors.New(http.StatusText(statusCode)), false).WithReason("not_found")
+	oops.Method = method
+	oops.URL = rawURL
+	return oops
+}
+
+// TestOops_Route tests IDs in the path are replaced with ":id"
+// TestOops_Route 测试路径中的 ID 被替换为 ":id"
+func TestOops_Route(t *testing.T) {
+	cases := map[string]string{
+		"https://api.example.com/v1/users/42/orders":                                 "GET /v1/users/:id/orders",
+		"https://api.example.com/v1/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8":      "GET /v1/users/:id",
+		"https://api.example.com/blobs/9f86d081884c7d659a2feaa0c55ad015a3bf4f1b?x=1": "GET /blobs/:id",
+		"https://api.example.com/oauth2/token":                                       "GET /oauth2/token",
+		"https://api.example.com/items/ord_1A2b3C4d5E6f7G8h":                         "GET /items/:id",
+	}
+	for rawURL, route := range cases {
+		require.Equal(t, route, newRequestOops(http.MethodGet, rawURL, 404).Route(), rawURL)
+	}
+}
+
+// TestOops_Fingerprint tests identical failures on different IDs share the fingerprint
+// TestOops_Fingerprint 测试不同 ID 上相同的失败共享指纹
+func TestOops_Fingerprint(t *testing.T) {
+	first := newRequestOops(http.MethodGet, "https://api.example.com/users/42?token=REDACTED", 404)
+	second := newRequestOops(http.MethodGet, "https://API.example.com/users/1001", 404)
+	require.Equal(t, first.Fingerprint(), second.Fingerprint())
+	require.Len(t, first.Fingerprint(), 16)
+
+	require.NotEqual(t, first.Fingerprint(), newRequestOops(http.MethodGet, "https://api.example.com/users/42", 410).Fingerprint())
+	require.NotEqual(t, first.Fingerprint(), newRequestOops(http.MethodDelete, "https://api.example.com/users/42", 404).Fingerprint())
+	require.NotEqual(t, first.Fingerprint(), newRequestOops(http.MethodGet, "https://pay.example.com/users/42", 404).Fingerprint())
+	require.NotEqual(t, first.Fingerprint(), newRequestOops(http.MethodGet, "https://api.example.com/users/42", 404).WithBusinessCode("1001").Fingerprint())
+
+	// Detected failures fingerprint the same across IDs
+	// 检测到的失败在不同 ID 间指纹相同
+	require.Equal(t,
+		detectServed(t, restyoops.NewConfig(), http.StatusServiceUnavailable, "").Fingerprint(),
+		detectServed(t, restyoops.NewConfig(), http.StatusServiceUnavailable, "busy").Fingerprint(),
+	)
+}
@@ -0,0 +1,101 @@
+package restyoops
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/yyle88/restyoops/internal/utils"
+)
+
+// Hook receives each classified failure together with the request context
+// Hook 接收每个已分类的失败以及请求上下文
+type Hook func(ctx context.Context, oops *Oops)
+
+// Attach registers the hooks on the client, so each failed attempt is classified and passed to them
+// Responses are classified per attempt, transport failures when seen by a retry hook or on giving up
+// Failures followed by a retry reach the hooks from a retry hook, the final failure is marked as exhausted
+// and carries the attempt history when retried
+//
+// Attach 在客户端上注册钩子，每次失败的尝试都会被分类并传递给钩子
+// 响应按每次尝试分类，传输失败在重试钩子中或最终放弃时分类
+// 之后会重试的失败由重试钩子传递给钩子，最终失败会被标记为已耗尽，重试过时附带尝试历史
+func (c *Detective) Attach(client *resty.Client, hooks ...Hook) *resty.Client {
+	return c.AttachWithSuccess(client, nil, hooks...)
+}
+
+// SuccessFunc receives each response classified as success
+// SuccessFunc 接收每个被分类为成功的响应
+type SuccessFunc func(resp *resty.Response)
+
+// AttachWithSuccess works like Attach, passing responses classified as success to onSuccess when not nil
+// Each response is classified once, so failures and successes come from the same decision
+//
+// AttachWithSuccess 与 Attach 相同，并在 onSuccess 不为 nil 时将分类为成功的响应传递给它
+// 每个响应只分类一次，因此失败和成功来自同一次判定
+func (c *Detective) AttachWithSuccess(client *resty.Client, onSuccess SuccessFunc, hooks ...Hook) *resty.Client {
+	tracker := NewTracker[*resty.Request]()
+	client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		oops := Detect(c.selectResponse(resp), resp, nil)
+		if oops == nil && onSuccess != nil {
+			onSuccess(resp)
+		}
+		tracker.SetPending(resp.Request, oops)
+		return nil
+	})
+	client.AddRetryHook(func(resp *resty.Response, respCause error) {
+		if resp == nil || resp.Request == nil {
+			return // failed before sending, no request to track
+		}
+		req := resp.Request
+		oops := tracker.TakePending(req)
+		if oops == nil && resp.RawResponse == nil && respCause != nil {
+			oops = Detect(c.Select(req), resp, respCause)
+		}
+		if oops == nil {
+			return
+		}
+		if req.Attempt > client.RetryCount {
+			tracker.SetPending(req, oops) // resty runs retry hooks after the last attempt too, no retry follows
+			return
+		}
+		tracker.AddAttempt(req, NewAttempt(oops, resp.Time()))
+		RunHooks(req.Context(), oops, hooks)
+	})
+	client.OnSuccess(func(_ *resty.Client, resp *resty.Response) {
+		if oops := tracker.TakePending(resp.Request); oops != nil {
+			RunHooks(resp.Request.Context(), tracker.Finish(resp.Request, oops, resp.Time()), hooks)
+		}
+		tracker.Forget(resp.Request)
+	})
+	client.OnError(func(req *resty.Request, respCause error) {
+		defer tracker.Forget(req)
+		var resp *resty.Response
+		if respErr, ok := utils.ErrorsAs[*resty.ResponseError](respCause); ok {
+			resp, respCause = respErr.Response, respErr.Err
+		}
+		oops := tracker.TakePending(req) // a response was received and classified
+		if oops == nil {
+			if resp != nil && resp.RawResponse != nil {
+				return // response received and classified as success, or already passed to the hooks
+			}
+			oops = Detect(c.Select(req), resp, respCause)
+		}
+		if oops != nil {
+			var duration time.Duration
+			if resp != nil {
+				duration = resp.Time()
+			}
+			RunHooks(req.Context(), tracker.Finish(req, oops, duration), hooks)
+		}
+	})
+	return client
+}
+
+// RunHooks invokes the hooks in sequence, for integrations passing failures to hooks
+// RunHooks 按顺序调用钩子，供将失败传递给钩子的集成使用
+func RunHooks(ctx context.Context, oops *Oops, hooks []Hook) {
+	for _, hook := range hooks {
+		hook(ctx, oops)
+	}
+}
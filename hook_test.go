@@ -0,0 +1,128 @@
+package restyoops_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestDetective_Attach tests hooks receive each failed attempt once
+// TestDetective_Attach 测试钩子对每次失败的尝试只接收一次
+func TestDetective_Attach(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var issues []*restyoops.Oops
+	hook := func(ctx context.Context, oops *restyoops.Oops) {
+		require.NotNil(t, ctx)
+		issues = append(issues, oops)
+	}
+
+	client := resty.New().
+		SetRetryCount(2).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			return resp.StatusCode() == http.StatusServiceUnavailable
+		})
+	restyoops.NewDetective(restyoops.NewConfig()).Attach(client, hook)
+
+	resp, err := client.R().Get(server.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	require.Len(t, issues, 3) // one per attempt
+	for _, oops := range issues {
+		require.Equal(t, restyoops.KindHttp, oops.Kind)
+		require.Equal(t, "service_unavailable", oops.Reason)
+	}
+	require.False(t, issues[0].Exhausted)
+	require.False(t, issues[1].Exhausted)
+	require.True(t, issues[2].Exhausted)
+	require.Len(t, issues[2].Attempts, 3)
+}
+
+// TestDetective_Attach_NoRetryCondition tests a failure resty does not retry is final, though retries are left
+// TestDetective_Attach_NoRetryCondition 测试 resty 不重试的失败即为最终失败，即使仍有剩余重试次数
+func TestDetective_Attach_NoRetryCondition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var issues []*restyoops.Oops
+	client := restyoops.NewDetective(restyoops.NewConfig()).Attach(resty.New().SetRetryCount(2), func(ctx context.Context, oops *restyoops.Oops) {
+		issues = append(issues, oops)
+	})
+
+	_, err := client.R().Get(server.URL)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	require.True(t, issues[0].Retryable)
+	require.True(t, issues[0].Exhausted)
+	require.True(t, restyoops.ShouldReport(issues[0]))
+	require.Empty(t, issues[0].Attempts)
+}
+
+// TestDetective_Attach_NetworkIssue tests hooks receive transport failures with request context
+// TestDetective_Attach_NetworkIssue 测试钩子接收带有请求上下文的传输失败
+func TestDetective_Attach_NetworkIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close() // connection refused from now on
+
+	var issues []*restyoops.Oops
+	client := restyoops.NewDetective(restyoops.NewConfig()).Attach(resty.New(), func(ctx context.Context, oops *restyoops.Oops) {
+		issues = append(issues, oops)
+	})
+
+	_, err := client.R().Get(serverURL + "/orders")
+	require.Error(t, err)
+	require.Len(t, issues, 1)
+	require.Equal(t, restyoops.KindNetwork, issues[0].Kind)
+	require.Equal(t, restyoops.ReasonConnection, issues[0].Reason)
+	require.True(t, issues[0].Retryable)
+	require.Equal(t, serverURL+"/orders", issues[0].URL)
+}
+
+// TestDetective_AttachWithSuccess tests each response is classified once, passed to onSuccess or to the hooks
+// TestDetective_AttachWithSuccess 测试每个响应只分类一次，并传递给 onSuccess 或钩子
+func TestDetective_AttachWithSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	var checks int
+	cfg := restyoops.NewConfig().WithContentCheck(http.StatusOK, func(contentType string, content []byte) *restyoops.Oops {
+		checks++
+		if string(content) == "/bad" {
+			return restyoops.NewOops(restyoops.KindBusiness, http.StatusOK, errors.New("bad"), false)
+		}
+		return nil
+	})
+	var successes []*resty.Response
+	var issues []*restyoops.Oops
+	client := restyoops.NewDetective(cfg).AttachWithSuccess(resty.New(), func(resp *resty.Response) {
+		successes = append(successes, resp)
+	}, func(ctx context.Context, oops *restyoops.Oops) {
+		issues = append(issues, oops)
+	})
+
+	for _, path := range []string{"/ok", "/bad"} {
+		_, err := client.R().Get(server.URL + path)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, checks)
+	require.Len(t, successes, 1)
+	require.Equal(t, "/ok", successes[0].String())
+	require.Len(t, issues, 1)
+	require.Equal(t, restyoops.KindBusiness, issues[0].Kind)
+}
@@ -0,0 +1,152 @@
+// Package matrix: shared classification cases, run against each supported HTTP client to prove identical results
+// matrix: 共享的分类用例，在每个支持的 HTTP 客户端上运行以证明结果一致
+package matrix
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/yyle88/restyoops"
+)
+
+// Case is one classification scenario with the expected outcome
+// Case 是一个带有预期结果的分类场景
+type Case struct {
+	Name      string                   // Case name // 用例名称
+	Handler   http.HandlerFunc         // Server handler, nil means the connection is refused // 服务端处理函数，nil 表示拒绝连接
+	Timeout   time.Duration            // Client timeout, 0 means none // 客户端超时，0 表示不设置
+	NewConfig func() *restyoops.Config // Config of the case // 用例的 Config
+
+	Kind      restyoops.Kind // Expected Kind, empty means success // 预期的 Kind，空表示成功
+	Retryable bool           // Expected retryable // 预期是否可重试
+	Reason    string         // Expected reason // 预期的原因
+}
+
+// Serve starts the server of the case, returning its URL and the stop function
+// Serve 启动用例的服务端，返回其 URL 和停止函数
+func (c *Case) Serve() (string, func()) {
+	if c.Handler == nil {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		return server.URL, func() {}
+	}
+	server := httptest.NewServer(c.Handler)
+	return server.URL, server.Close
+}
+
+// Cases returns the shared classification cases
+// Cases 返回共享的分类用例
+func Cases() []*Case {
+	return []*Case{
+		{
+			Name:      "success",
+			Handler:   writeStatus(http.StatusOK, "ok"),
+			NewConfig: restyoops.NewConfig,
+		},
+		{
+			Name:      "service_unavailable",
+			Handler:   writeStatus(http.StatusServiceUnavailable, "busy"),
+			NewConfig: restyoops.NewConfig,
+			Kind:      restyoops.KindHttp,
+			Retryable: true,
+			Reason:    "service_unavailable",
+		},
+		{
+			Name:      "not_found",
+			Handler:   writeStatus(http.StatusNotFound, "missing"),
+			NewConfig: restyoops.NewConfig,
+			Kind:      restyoops.KindHttp,
+			Retryable: false,
+			Reason:    "not_found",
+		},
+		{
+			Name:    "status_option",
+			Handler: writeStatus(http.StatusForbidden, "denied"),
+			NewConfig: func() *restyoops.Config {
+				return restyoops.NewConfig().WithStatusRetryable(http.StatusForbidden, true, 0)
+			},
+			Kind:      restyoops.KindHttp,
+			Retryable: true,
+			Reason:    "forbidden",
+		},
+		{
+			Name:    "content_check",
+			Handler: writeStatus(http.StatusOK, `{"code":1001}`),
+			NewConfig: func() *restyoops.Config {
+				return restyoops.NewConfig().WithContentCheck(http.StatusOK, func(contentType string, content []byte) *restyoops.Oops {
+					if string(content) != `{"code":1001}` {
+						return nil
+					}
+					return restyoops.NewOops(restyoops.KindBusiness, http.StatusOK, errors.New("code 1001"), false).WithReason("business_code")
+				})
+			},
+			Kind:      restyoops.KindBusiness,
+			Retryable: false,
+			Reason:    "business_code",
+		},
+		{
+			Name: "header_check",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			NewConfig: func() *restyoops.Config {
+				return restyoops.NewConfig().WithHeaderCheck(http.StatusForbidden, func(header http.Header, content []byte) *restyoops.Oops {
+					if header.Get("X-RateLimit-Remaining") != "0" {
+						return nil
+					}
+					return restyoops.NewOops(restyoops.KindHttp, http.StatusForbidden, errors.New("rate limited"), true).WithReason("rate_limited")
+				})
+			},
+			Kind:      restyoops.KindHttp,
+			Retryable: true,
+			Reason:    "rate_limited",
+		},
+		{
+			Name: "retry_directive",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Should-Retry", "false")
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			NewConfig: func() *restyoops.Config {
+				return restyoops.NewConfig().WithRetryDirectiveHeaders("X-Should-Retry")
+			},
+			Kind:      restyoops.KindHttp,
+			Retryable: false,
+			Reason:    "internal_server_error",
+		},
+		{
+			Name: "timeout",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			Timeout:   50 * time.Millisecond,
+			NewConfig: restyoops.NewConfig,
+			Kind:      restyoops.KindNetwork,
+			Retryable: true,
+			Reason:    restyoops.ReasonTimeout,
+		},
+		{
+			Name:      "connection_refused",
+			Handler:   nil,
+			NewConfig: restyoops.NewConfig,
+			Kind:      restyoops.KindNetwork,
+			Retryable: true,
+			Reason:    restyoops.ReasonConnection,
+		},
+	}
+}
+
+// writeStatus creates a handler writing the status code and body
+// writeStatus 创建写入状态码和响应体的处理函数
+func writeStatus(statusCode int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}
+}
@@ -0,0 +1,648 @@
+package restyoops
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/yyle88/restyoops/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat names a serialised config format
+// ConfigFormat 表示序列化配置的格式
+type ConfigFormat string
+
+const (
+	FormatYAML ConfigFormat = "yaml" // YAML document // YAML 文档
+	FormatJSON ConfigFormat = "json" // JSON document // JSON 文档
+	FormatTOML ConfigFormat = "toml" // TOML document // TOML 文档
+)
+
+// ConfigSpec is the serialisable form of Config
+// ConfigSpec 是 Config 的可序列化形式
+type ConfigSpec struct {
+	DefaultWait   *SpecDuration       `yaml:"default_wait" toml:"default_wait" json:"default_wait"`
+	Status        []*StatusSpec       `yaml:"status" toml:"status" json:"status"`
+	Kinds         []*KindSpec         `yaml:"kinds" toml:"kinds" json:"kinds"`
+	ContentChecks []*ContentCheckSpec `yaml:"content_checks" toml:"content_checks" json:"content_checks"`
+	Severities    []*SeveritySpec     `yaml:"severities" toml:"severities" json:"severities"`
+}
+
+// StatusSpec is the serialisable form of a status code rule
+// StatusSpec 是状态码规则的可序列化形式
+type StatusSpec struct {
+	Code      int          `yaml:"code" toml:"code" json:"code"`
+	Retryable bool         `yaml:"retryable" toml:"retryable" json:"retryable"`
+	Wait      SpecDuration `yaml:"wait" toml:"wait" json:"wait"`
+}
+
+// KindSpec is the serialisable form of a Kind rule
+// KindSpec 是 Kind 规则的可序列化形式
+type KindSpec struct {
+	Kind      Kind         `yaml:"kind" toml:"kind" json:"kind"`
+	Retryable bool         `yaml:"retryable" toml:"retryable" json:"retryable"`
+	Wait      SpecDuration `yaml:"wait" toml:"wait" json:"wait"`
+}
+
+// ContentCheckSpec is a declarative content check, matching either a JSON business code or a body regex
+// ContentCheckSpec 是声明式内容检查，匹配 JSON 业务码或响应体正则之一
+type ContentCheckSpec struct {
+	Status    int          `yaml:"status" toml:"status" json:"status"`
+	JSONPath  string       `yaml:"json_path" toml:"json_path" json:"json_path"`    // dotted path of business code // 业务码的点分路径
+	Success   []string     `yaml:"success" toml:"success" json:"success"`          // business codes meaning success // 表示成功的业务码
+	BodyRegex string       `yaml:"body_regex" toml:"body_regex" json:"body_regex"` // regex matching the body // 匹配响应体的正则
+	Kind      Kind         `yaml:"kind" toml:"kind" json:"kind"`
+	Retryable bool         `yaml:"retryable" toml:"retryable" json:"retryable"`
+	Wait      SpecDuration `yaml:"wait" toml:"wait" json:"wait"`
+	Reason    string       `yaml:"reason" toml:"reason" json:"reason"`
+}
+
+// SeveritySpec is the serialisable form of a severity rule, matching exactly one of kind, status, reason and business code
+// SeveritySpec 是严重程度规则的可序列化形式，只匹配 kind、status、reason、business_code 之一
+type SeveritySpec struct {
+	Kind         Kind     `yaml:"kind" toml:"kind" json:"kind"`
+	Status       int      `yaml:"status" toml:"status" json:"status"`
+	Reason       string   `yaml:"reason" toml:"reason" json:"reason"`
+	BusinessCode string   `yaml:"business_code" toml:"business_code" json:"business_code"`
+	Severity     Severity `yaml:"severity" toml:"severity" json:"severity"`
+}
+
+// SpecDuration is a time.Duration written as a string like "1.5s"
+// SpecDuration 是以字符串形式书写的 time.Duration，如 "1.5s"
+type SpecDuration time.Duration
+
+// UnmarshalText parses the duration string, used by TOML
+// UnmarshalText 解析时长字符串，供 TOML 使用
+func (d *SpecDuration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = SpecDuration(value)
+	return nil
+}
+
+// UnmarshalYAML parses the duration string, used by YAML and JSON
+// UnmarshalYAML 解析时长字符串，供 YAML 和 JSON 使用
+func (d *SpecDuration) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: duration must be a string like \"1s\"", node.Line)
+	}
+	if err := d.UnmarshalText([]byte(node.Value)); err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	return nil
+}
+
+// ConfigError reports an invalid entry in a serialised config
+// ConfigError 报告序列化配置中的无效条目
+type ConfigError struct {
+	Line int    // Line number, 0 when unknown // 行号，未知时为 0
+	Path string // Entry path, like "status[1]" // 条目路径，如 "status[1]"
+	Msg  string // Description // 描述
+}
+
+// Error returns the message, like "line 7: status[1]: invalid status code 99"
+// Error 返回消息，如 "line 7: status[1]: invalid status code 99"
+func (e *ConfigError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// LoadConfig reads a YAML config, JSON documents are accepted too
+// LoadConfig 读取 YAML 配置，同样接受 JSON 文档
+func LoadConfig(r io.Reader) (*Config, error) {
+	return LoadConfigFormat(r, FormatYAML)
+}
+
+// LoadConfigFile reads a config file, the format chosen by extension (.yaml, .yml, .json, .toml)
+// LoadConfigFile 读取配置文件，根据扩展名选择格式（.yaml、.yml、.json、.toml）
+func LoadConfigFile(path string) (*Config, error) {
+	var format ConfigFormat
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = FormatYAML
+	case ".json":
+		format = FormatJSON
+	case ".toml":
+		format = FormatTOML
+	default:
+		return nil, fmt.Errorf("unknown config format of file %q", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadConfigFormat(file, format)
+}
+
+// LoadConfigFormat reads a config in the format and validates it strictly
+// LoadConfigFormat 读取指定格式的配置并进行严格校验
+func LoadConfigFormat(r io.Reader, format ConfigFormat) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	spec, lines, err := decodeSpec(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return spec.build(lines)
+}
+
+// decodeSpec decodes the document and returns a lookup of entry lines
+// decodeSpec 解码文档并返回条目行号查找函数
+func decodeSpec(data []byte, format ConfigFormat) (*ConfigSpec, func(path string) int, error) {
+	spec := &ConfigSpec{}
+	switch format {
+	case FormatYAML:
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, nil, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(spec); err != nil && !errors.Is(err, io.EOF) {
+			return nil, nil, err
+		}
+		return spec, yamlLines(&doc), nil
+	case FormatJSON:
+		lines, fields := jsonLines(data)
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(spec); err != nil && !errors.Is(err, io.EOF) {
+			return nil, nil, jsonError(data, fields, err)
+		}
+		return spec, lines, nil
+	case FormatTOML:
+		lines := tomlLines(data)
+		meta, err := toml.Decode(string(data), spec)
+		if err != nil {
+			if parseErr, ok := utils.ErrorsAs[toml.ParseError](err); ok {
+				return nil, nil, &ConfigError{Line: parseErr.Position.Line, Path: parseErr.LastKey, Msg: parseErr.Message}
+			}
+			return nil, nil, err
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			path := undecoded[0].String()
+			return nil, nil, &ConfigError{Line: lines(path), Path: path, Msg: "unknown field"}
+		}
+		return spec, lines, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown config format %q", format)
+	}
+}
+
+// yamlLines returns a lookup of lines of top-level keys and sequence items like "status[1]"
+// yamlLines 返回顶层键和序列条目（如 "status[1]"）的行号查找函数
+func yamlLines(doc *yaml.Node) func(path string) int {
+	lines := map[string]int{}
+	if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+		root := doc.Content[0]
+		for idx := 0; idx+1 < len(root.Content); idx += 2 {
+			key, value := root.Content[idx], root.Content[idx+1]
+			lines[key.Value] = key.Line
+			for pos, item := range value.Content {
+				if value.Kind == yaml.SequenceNode {
+					lines[fmt.Sprintf("%s[%d]", key.Value, pos)] = item.Line
+				}
+			}
+		}
+	}
+	return func(path string) int {
+		return lines[path]
+	}
+}
+
+// jsonLines returns a lookup of lines of top-level keys and array items like "status[1]",
+// together with the line of the first occurrence of each key at any depth
+//
+// jsonLines 返回顶层键和数组条目（如 "status[1]"）的行号查找函数，
+// 以及每个键在任意深度首次出现的行号
+func jsonLines(data []byte) (func(path string) int, map[string]int) {
+	lines := map[string]int{}
+	fields := map[string]int{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var stack []json.Delim // open objects and arrays // 已打开的对象和数组
+	var keys []string      // key of each open container at depth 1 // 深度 1 上每个已打开容器的键
+	index := map[string]int{}
+	expectKey := false
+	lastKey := ""
+	for {
+		start := skipJSONSpace(data, decoder.InputOffset())
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		line := lineAt(data, start)
+		inObject := len(stack) > 0 && stack[len(stack)-1] == '{'
+		if key, ok := token.(string); ok && inObject && expectKey {
+			if _, seen := fields[key]; !seen {
+				fields[key] = line
+			}
+			if len(stack) == 1 {
+				lines[key] = line
+			}
+			lastKey = key
+			expectKey = false
+			continue
+		}
+		// Items of top-level arrays, like status[1]
+		// 顶层数组的条目，如 status[1]
+		if len(stack) == 2 && stack[1] == '[' && token != json.Delim(']') {
+			name := keys[len(keys)-1]
+			lines[fmt.Sprintf("%s[%d]", name, index[name])] = line
+			index[name]++
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if len(stack) == 1 {
+				keys = append(keys, lastKey)
+			}
+			stack = append(stack, token.(json.Delim))
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if len(stack) == 1 {
+				keys = keys[:len(keys)-1]
+			}
+		}
+		expectKey = len(stack) > 0 && stack[len(stack)-1] == '{'
+	}
+	return func(path string) int {
+		return lines[path]
+	}, fields
+}
+
+// jsonError converts the JSON decoding error into a ConfigError carrying the line
+// jsonError 将 JSON 解码错误转换为带有行号的 ConfigError
+func jsonError(data []byte, fields map[string]int, err error) error {
+	if syntaxErr, ok := utils.ErrorsAs[*json.SyntaxError](err); ok {
+		return &ConfigError{Line: lineAt(data, syntaxErr.Offset), Path: "json", Msg: syntaxErr.Error()}
+	}
+	if typeErr, ok := utils.ErrorsAs[*json.UnmarshalTypeError](err); ok {
+		return &ConfigError{Line: lineAt(data, typeErr.Offset), Path: jsonPath(typeErr.Field), Msg: fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)}
+	}
+	// Unknown fields are reported like `json: unknown field "retry"`
+	// 未知字段的报告形如 `json: unknown field "retry"`
+	if quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if name, unquoteErr := strconv.Unquote(quoted); unquoteErr == nil {
+			return &ConfigError{Line: fields[name], Path: name, Msg: "unknown field"}
+		}
+	}
+	return err
+}
+
+// jsonPath converts a dotted JSON field path like "status.0.code" into "status[0].code"
+// jsonPath 将点分 JSON 字段路径（如 "status.0.code"）转换为 "status[0].code"
+func jsonPath(field string) string {
+	var res strings.Builder
+	for idx, segment := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(segment); err == nil {
+			res.WriteString("[" + segment + "]")
+			continue
+		}
+		if idx > 0 {
+			res.WriteByte('.')
+		}
+		res.WriteString(segment)
+	}
+	return res.String()
+}
+
+// skipJSONSpace returns the offset of the next token, skipping whitespace and separators
+// skipJSONSpace 跳过空白和分隔符，返回下一个标记的偏移量
+func skipJSONSpace(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// tomlLines returns a lookup of lines of keys like "default_wait" or "status.retry",
+// and of array table items like "status[1]"
+//
+// tomlLines 返回键（如 "default_wait"、"status.retry"）
+// 以及数组表条目（如 "status[1]"）的行号查找函数
+func tomlLines(data []byte) func(path string) int {
+	lines := map[string]int{}
+	counts := map[string]int{}
+	record := func(path string, line int) {
+		if _, ok := lines[path]; !ok {
+			lines[path] = line
+		}
+	}
+	table := ""
+	for idx, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimSpace(text)
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, "[["):
+			header, _, _ := strings.Cut(text, "#")
+			table = strings.TrimSpace(strings.Trim(strings.TrimSpace(header), "[]"))
+			record(table, idx+1)
+			record(fmt.Sprintf("%s[%d]", table, counts[table]), idx+1)
+			counts[table]++
+		case strings.HasPrefix(text, "["):
+			header, _, _ := strings.Cut(text, "#")
+			table = strings.TrimSpace(strings.Trim(strings.TrimSpace(header), "[]"))
+			record(table, idx+1)
+		default:
+			if key, _, ok := strings.Cut(text, "="); ok {
+				key = strings.Trim(strings.TrimSpace(key), `"'`)
+				if table != "" {
+					key = table + "." + key
+				}
+				record(key, idx+1)
+			}
+		}
+	}
+	return func(path string) int {
+		return lines[path]
+	}
+}
+
+// lineAt returns the 1-based line number of the byte offset
+// lineAt 返回字节偏移量所在的行号，从 1 开始
+func lineAt(data []byte, offset int64) int {
+	offset = min(max(offset, 0), int64(len(data)))
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// build validates the spec and converts it into Config, reporting each invalid entry
+// build 校验规格并转换为 Config，报告每个无效条目
+func (spec *ConfigSpec) build(lines func(path string) int) (*Config, error) {
+	var errs []error
+	report := func(path string, format string, args ...any) {
+		errs = append(errs, &ConfigError{Line: lines(path), Path: path, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	cfg := NewConfig()
+	if spec.DefaultWait != nil {
+		if *spec.DefaultWait < 0 {
+			report("default_wait", "negative duration")
+		}
+		cfg.WithDefaultWait(time.Duration(*spec.DefaultWait))
+	}
+
+	for idx, item := range spec.Status {
+		path := fmt.Sprintf("status[%d]", idx)
+		if !isValidStatusCode(item.Code) {
+			report(path, "invalid status code %d", item.Code)
+			continue
+		}
+		if _, ok := cfg.StatusOptions[item.Code]; ok {
+			report(path, "duplicate status code %d", item.Code)
+			continue
+		}
+		if item.Wait < 0 {
+			report(path, "negative wait")
+			continue
+		}
+		cfg.WithStatusRetryable(item.Code, item.Retryable, time.Duration(item.Wait))
+	}
+
+	for idx, item := range spec.Kinds {
+		path := fmt.Sprintf("kinds[%d]", idx)
+		if !item.Kind.IsRegistered() {
+			report(path, "unknown kind %q", item.Kind)
+			continue
+		}
+		if _, ok := cfg.KindOptions[item.Kind]; ok {
+			report(path, "duplicate kind %s", item.Kind)
+			continue
+		}
+		if item.Wait < 0 {
+			report(path, "negative wait")
+			continue
+		}
+		cfg.WithKindRetryable(item.Kind, item.Retryable, time.Duration(item.Wait))
+	}
+
+	checks := map[int][]ContentCheckFunc{}
+	var statusCodes []int
+	for idx, item := range spec.ContentChecks {
+		path := fmt.Sprintf("content_checks[%d]", idx)
+		check, err := item.compile()
+		if err != nil {
+			report(path, "%s", err.Error())
+			continue
+		}
+		if _, ok := checks[item.Status]; !ok {
+			statusCodes = append(statusCodes, item.Status)
+		}
+		checks[item.Status] = append(checks[item.Status], check)
+	}
+	for _, statusCode := range statusCodes {
+		cfg.WithContentCheck(statusCode, chainContentChecks(checks[statusCode]))
+	}
+
+	for idx, item := range spec.Severities {
+		path := fmt.Sprintf("severities[%d]", idx)
+		if err := item.apply(cfg); err != nil {
+			report(path, "%s", err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+// apply validates the severity rule and sets it on the Config
+// apply 校验严重程度规则并将其设置到 Config
+func (spec *SeveritySpec) apply(cfg *Config) error {
+	if !spec.Severity.IsValid() {
+		return fmt.Errorf("unknown severity %q", spec.Severity)
+	}
+	selectors := 0
+	for _, set := range []bool{spec.Kind != "", spec.Status != 0, spec.Reason != "", spec.BusinessCode != ""} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return errors.New("exactly one of kind, status, reason and business_code is required")
+	}
+	switch {
+	case spec.Kind != "":
+		if !spec.Kind.IsRegistered() {
+			return fmt.Errorf("unknown kind %q", spec.Kind)
+		}
+		cfg.WithKindSeverity(spec.Kind, spec.Severity)
+	case spec.Status != 0:
+		if !isValidStatusCode(spec.Status) {
+			return fmt.Errorf("invalid status code %d", spec.Status)
+		}
+		cfg.WithStatusSeverity(spec.Status, spec.Severity)
+	case spec.Reason != "":
+		cfg.WithReasonSeverity(spec.Reason, spec.Severity)
+	default:
+		cfg.WithBusinessCodeSeverity(spec.BusinessCode, spec.Severity)
+	}
+	return nil
+}
+
+// compile validates the declarative check and converts it into a ContentCheckFunc
+// Without a wait, the Oops takes the DefaultWait of the Config detecting it
+//
+// compile 校验声明式检查并转换为 ContentCheckFunc
+// 未设置等待时间时，Oops 使用检测所用 Config 的 DefaultWait
+func (spec *ContentCheckSpec) compile() (ContentCheckFunc, error) {
+	if !isValidStatusCode(spec.Status) {
+		return nil, fmt.Errorf("invalid status code %d", spec.Status)
+	}
+	if (spec.JSONPath == "") == (spec.BodyRegex == "") {
+		return nil, errors.New("exactly one of json_path and body_regex is required")
+	}
+	if spec.Wait < 0 {
+		return nil, errors.New("negative wait")
+	}
+	waitTime := time.Duration(spec.Wait)
+
+	if spec.JSONPath != "" {
+		if len(spec.Success) == 0 {
+			return nil, errors.New("success codes are required with json_path")
+		}
+		kind := spec.Kind
+		if kind == "" {
+			kind = KindBusiness
+		}
+		if !kind.IsRegistered() {
+			return nil, fmt.Errorf("unknown kind %q", kind)
+		}
+		reason := spec.Reason
+		if reason == "" {
+			reason = "business_code"
+		}
+		return func(contentType string, content []byte) *Oops {
+			code, ok := lookupJSONPath(content, spec.JSONPath)
+			if !ok || isOneOf(code, spec.Success) {
+				return nil
+			}
+			cause := fmt.Errorf("business code %s=%s", spec.JSONPath, code)
+			oops := NewOops(kind, spec.Status, cause, spec.Retryable).
+				WithWaitTime(waitTime).
+				WithContentType(contentType).
+				WithReason(reason).
+				WithBusinessCode(code)
+			if waitTime == 0 {
+				oops.WithDefaultWait()
+			}
+			return oops
+		}, nil
+	}
+
+	pattern, err := regexp.Compile(spec.BodyRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body_regex: %w", err)
+	}
+	if !spec.Kind.IsRegistered() {
+		return nil, fmt.Errorf("unknown kind %q", spec.Kind)
+	}
+	reason := spec.Reason
+	if reason == "" {
+		reason = "body_match"
+	}
+	return func(contentType string, content []byte) *Oops {
+		if !pattern.Match(content) {
+			return nil
+		}
+		cause := fmt.Errorf("body matches %q", spec.BodyRegex)
+		oops := NewOops(spec.Kind, spec.Status, cause, spec.Retryable).
+			WithWaitTime(waitTime).
+			WithContentType(contentType).
+			WithReason(reason)
+		if waitTime == 0 {
+			oops.WithDefaultWait()
+		}
+		return oops
+	}, nil
+}
+
+// chainContentChecks runs checks in sequence, the first Oops wins
+// chainContentChecks 按顺序运行检查，第一个 Oops 生效
+func chainContentChecks(checks []ContentCheckFunc) ContentCheckFunc {
+	if len(checks) == 1 {
+		return checks[0]
+	}
+	return func(contentType string, content []byte) *Oops {
+		for _, check := range checks {
+			if oops := check(contentType, content); oops != nil {
+				return oops
+			}
+		}
+		return nil
+	}
+}
+
+// lookupJSONPath finds the value at a dotted path like "data.items.0.code" and formats it as string
+// lookupJSONPath 查找点分路径（如 "data.items.0.code"）上的值并格式化为字符串
+func lookupJSONPath(content []byte, path string) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			item, ok := v[segment]
+			if !ok {
+				return "", false
+			}
+			value = item
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return "", false
+			}
+			value = v[idx]
+		default:
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "null", true
+	default:
+		return "", false
+	}
+}
+
+// isOneOf checks if the value is in the list
+// isOneOf 检查值是否在列表中
+func isOneOf(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidStatusCode checks if the status code is in the HTTP range
+// isValidStatusCode 检查状态码是否在 HTTP 范围内
+func isValidStatusCode(statusCode int) bool {
+	return statusCode >= 100 && statusCode <= 599
+}
@@ -0,0 +1,188 @@
+package restyoops_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+const configYAML = `
+default_wait: 2s
+status:
+  - code: 403
+    retryable: true
+    wait: 5s
+kinds:
+  - kind: NETWORK
+    retryable: false
+content_checks:
+  - status: 200
+    json_path: data.code
+    success: [0, "OK"]
+    retryable: true
+    reason: quota_exceeded
+  - status: 200
+    body_regex: "(?i)captcha"
+    kind: BLOCK
+`
+
+// detectServed serves the body with the status code and returns the detected Oops
+// detectServed 以指定状态码返回响应体并返回检测得到的 Oops
+func detectServed(t *testing.T, cfg *restyoops.Config, statusCode int, body string) *restyoops.Oops {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	resp, err := resty.New().R().Get(server.URL)
+	return restyoops.Detect(cfg, resp, err)
+}
+
+// TestLoadConfig tests YAML rules and declarative content checks are applied
+// TestLoadConfig 测试 YAML 规则和声明式内容检查生效
+func TestLoadConfig(t *testing.T) {
+	cfg, err := restyoops.LoadConfig(strings.NewReader(configYAML))
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Second, cfg.DefaultWait)
+	require.Equal(t, 5*time.Second, cfg.StatusOptions[403].WaitTime)
+	require.False(t, cfg.KindOptions[restyoops.KindNetwork].Retryable)
+
+	oops := detectServed(t, cfg, 200, `{"data":{"code":1001}}`)
+	require.Equal(t, restyoops.KindBusiness, oops.Kind)
+	require.Equal(t, "quota_exceeded", oops.Reason)
+	require.True(t, oops.Retryable)
+	require.Equal(t, 2*time.Second, oops.WaitTime)
+	require.Contains(t, oops.Cause.Error(), "data.code=1001")
+
+	require.Nil(t, detectServed(t, cfg, 200, `{"data":{"code":0}}`))
+	require.Nil(t, detectServed(t, cfg, 200, `{"data":{"code":"OK"}}`))
+
+	oops = detectServed(t, cfg, 200, `<html>Please solve the CAPTCHA</html>`)
+	require.Equal(t, restyoops.KindBlock, oops.Kind)
+	require.Equal(t, "body_match", oops.Reason)
+	require.False(t, oops.Retryable)
+
+	// Checks without a wait follow the DefaultWait of the Config detecting them
+	// 未设置等待时间的检查使用检测所用 Config 的 DefaultWait
+	oops = detectServed(t, cfg.WithDefaultWait(7*time.Second), 200, `{"data":{"code":1001}}`)
+	require.Equal(t, 7*time.Second, oops.WaitTime)
+}
+
+// TestLoadConfigFile tests JSON and TOML files load into the same rules
+// TestLoadConfigFile 测试 JSON 和 TOML 文件加载为相同的规则
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cfg.json": "{\n\t\"default_wait\": \"3s\",\n\t\"status\": [{\"code\": 500, \"retryable\": false}]\n}",
+		"cfg.toml": "default_wait = \"3s\"\n\n[[status]]\ncode = 500\nretryable = false\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		cfg, err := restyoops.LoadConfigFile(path)
+		require.NoError(t, err, name)
+		require.Equal(t, 3*time.Second, cfg.DefaultWait, name)
+		require.False(t, cfg.StatusOptions[500].Retryable, name)
+	}
+
+	_, err := restyoops.LoadConfigFile(filepath.Join(dir, "cfg.ini"))
+	require.Error(t, err)
+
+	// Tabs inside JSON strings are kept as they are
+	// JSON 字符串中的制表符保持原样
+	cfg, err := restyoops.LoadConfigFormat(strings.NewReader(`{"content_checks": [{"status": 200, "body_regex": "a\tb", "kind": "BLOCK"}]}`), restyoops.FormatJSON)
+	require.NoError(t, err)
+	require.NotNil(t, detectServed(t, cfg, 200, "a\tb"))
+	require.Nil(t, detectServed(t, cfg, 200, "a b"))
+}
+
+// TestLoadConfig_Invalid tests each invalid entry is reported with its line
+// TestLoadConfig_Invalid 测试每个无效条目都带行号报告
+func TestLoadConfig_Invalid(t *testing.T) {
+	const invalid = `status:
+  - code: 403
+    retryable: true
+  - code: 99
+kinds:
+  - kind: CAPTCHA
+content_checks:
+  - status: 200
+    body_regex: "("
+    kind: BLOCK
+`
+	_, err := restyoops.LoadConfig(strings.NewReader(invalid))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 4: status[1]: invalid status code 99")
+	require.Contains(t, err.Error(), `line 6: kinds[0]: unknown kind "CAPTCHA"`)
+	require.Contains(t, err.Error(), "line 8: content_checks[0]: invalid body_regex")
+}
+
+// TestLoadConfig_InvalidLines tests invalid entries carry their lines in JSON and TOML too
+// TestLoadConfig_InvalidLines 测试 JSON 和 TOML 中的无效条目同样带有行号
+func TestLoadConfig_InvalidLines(t *testing.T) {
+	const invalidJSON = `{
+  "status": [
+    {"code": 403, "retryable": true},
+    {"code": 99}
+  ],
+  "kinds": [
+    {"kind": "CAPTCHA"}
+  ]
+}`
+	_, err := restyoops.LoadConfigFormat(strings.NewReader(invalidJSON), restyoops.FormatJSON)
+	require.ErrorContains(t, err, "line 4: status[1]: invalid status code 99")
+	require.ErrorContains(t, err, `line 7: kinds[0]: unknown kind "CAPTCHA"`)
+
+	const invalidTOML = `default_wait = "1s"
+
+[[status]]
+code = 403
+retryable = true
+
+[[status]]
+code = 99
+
+[[kinds]]
+kind = "CAPTCHA"
+`
+	_, err = restyoops.LoadConfigFormat(strings.NewReader(invalidTOML), restyoops.FormatTOML)
+	require.ErrorContains(t, err, "line 7: status[1]: invalid status code 99")
+	require.ErrorContains(t, err, `line 10: kinds[0]: unknown kind "CAPTCHA"`)
+
+	// Syntax and type errors carry the line too
+	// 语法错误和类型错误同样带有行号
+	_, err = restyoops.LoadConfigFormat(strings.NewReader("{\n  \"status\": [\n    {\"code\": \"x\"}\n  ]\n}"), restyoops.FormatJSON)
+	require.ErrorContains(t, err, "line 3: status[0].code: cannot use string as int")
+	_, err = restyoops.LoadConfigFormat(strings.NewReader("{\n  \"default_wait\": \"1s\",\n  ]\n}"), restyoops.FormatJSON)
+	require.ErrorContains(t, err, "line 3")
+	_, err = restyoops.LoadConfigFormat(strings.NewReader("[[status]]\ncode = 403\nretryable = yes\n"), restyoops.FormatTOML)
+	require.ErrorContains(t, err, "line 3")
+}
+
+// TestLoadConfig_UnknownField tests unknown fields are rejected in each format
+// TestLoadConfig_UnknownField 测试各格式都拒绝未知字段
+func TestLoadConfig_UnknownField(t *testing.T) {
+	_, err := restyoops.LoadConfig(strings.NewReader("status:\n  - code: 403\n    retry: true\n"))
+	require.ErrorContains(t, err, "line 3")
+	require.ErrorContains(t, err, "retry")
+
+	_, err = restyoops.LoadConfigFormat(strings.NewReader("[[status]]\ncode = 403\nretry = true\n"), restyoops.FormatTOML)
+	require.ErrorContains(t, err, "line 3: status.retry: unknown field")
+
+	_, err = restyoops.LoadConfigFormat(strings.NewReader("{\n  \"status\": [\n    {\"code\": 403, \"retry\": true}\n  ]\n}"), restyoops.FormatJSON)
+	require.ErrorContains(t, err, "line 3: retry: unknown field")
+
+	_, err = restyoops.LoadConfig(strings.NewReader("default_wait: soon\n"))
+	require.ErrorContains(t, err, "line 1")
+}
@@ -0,0 +1,31 @@
+package restyoops_test
+
+import (
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/internal/matrix"
+)
+
+// TestMatrix tests resty v2 responses against the shared classification cases
+// TestMatrix 测试 resty v2 响应在共享分类用例上的结果
+func TestMatrix(t *testing.T) {
+	for _, tc := range matrix.Cases() {
+		t.Run(tc.Name, func(t *testing.T) {
+			serverURL, stop := tc.Serve()
+			defer stop()
+
+			resp, err := resty.New().SetTimeout(tc.Timeout).R().Get(serverURL)
+			oops := restyoops.Detect(tc.NewConfig(), resp, err)
+			if tc.Kind == "" {
+				require.Nil(t, oops)
+				return
+			}
+			require.Equal(t, tc.Kind, oops.Kind)
+			require.Equal(t, tc.Retryable, oops.Retryable)
+			require.Equal(t, tc.Reason, oops.Reason)
+		})
+	}
+}
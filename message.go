@@ -0,0 +1,183 @@
+package restyoops
+
+import (
+	"github.com/yyle88/must"
+	"golang.org/x/text/language"
+)
+
+// Messages holds the end-user texts of one locale, safe to show in UIs
+// Lookup goes by reason, then status code, then Kind, then Default
+//
+// Messages 保存某个语言环境的终端用户文本，可安全展示在界面上
+// 依次按原因、状态码、Kind 查找，最后使用 Default
+type Messages struct {
+	Reasons  map[string]string // text per reason // 各原因的文本
+	Statuses map[int]string    // text per status code // 各状态码的文本
+	Kinds    map[Kind]string   // text per Kind // 各 Kind 的文本
+	Default  string            // text when nothing matches // 都不匹配时的文本
+}
+
+// NewMessages creates empty Messages with the default text
+// NewMessages 创建带有默认文本的空 Messages
+func NewMessages(defaultText string) *Messages {
+	return &Messages{
+		Reasons:  make(map[string]string),
+		Statuses: make(map[int]string),
+		Kinds:    make(map[Kind]string),
+		Default:  must.Nice(defaultText),
+	}
+}
+
+// WithReason sets the text of the reason
+// WithReason 设置该原因的文本
+func (m *Messages) WithReason(reason string, text string) *Messages {
+	m.Reasons[reason] = text
+	return m
+}
+
+// WithStatus sets the text of the status code
+// WithStatus 设置该状态码的文本
+func (m *Messages) WithStatus(statusCode int, text string) *Messages {
+	m.Statuses[statusCode] = text
+	return m
+}
+
+// WithKind sets the text of the Kind
+// WithKind 设置该 Kind 的文本
+func (m *Messages) WithKind(kind Kind, text string) *Messages {
+	m.Kinds[kind] = text
+	return m
+}
+
+// Message returns the text of the Oops
+// Message 返回 Oops 的文本
+func (m *Messages) Message(oops *Oops) string {
+	if text, ok := m.Reasons[oops.Reason]; ok && oops.Reason != "" {
+		return text
+	}
+	if text, ok := m.Statuses[oops.StatusCode]; ok && oops.StatusCode > 0 {
+		return text
+	}
+	if text, ok := m.Kinds[oops.Kind]; ok {
+		return text
+	}
+	return m.Default
+}
+
+// Catalog holds Messages per locale and picks the closest locale of the requested language
+// Set it up before use, it is safe in concurrent Message calls once set up
+//
+// Catalog 保存各语言环境的 Messages，并选择与请求语言最接近的语言环境
+// 需在使用前完成设置，设置完成后可安全用于并发的 Message 调用
+type Catalog struct {
+	locales map[language.Tag]*Messages
+	tags    []language.Tag // first one is the fallback // 第一个作为回退
+	matcher language.Matcher
+}
+
+// NewCatalog creates a Catalog with English and Chinese texts, English is the fallback
+// NewCatalog 创建带有英文和中文文本的 Catalog，英文作为回退
+func NewCatalog() *Catalog {
+	return (&Catalog{locales: make(map[language.Tag]*Messages)}).
+		WithLocale(language.English, NewEnglishMessages()).
+		WithLocale(language.Chinese, NewChineseMessages())
+}
+
+// WithLocale sets the Messages of the locale, replacing existing ones
+// WithLocale 设置该语言环境的 Messages，替换已有的设置
+func (c *Catalog) WithLocale(tag language.Tag, messages *Messages) *Catalog {
+	must.Full(messages)
+	if _, ok := c.locales[tag]; !ok {
+		c.tags = append(c.tags, tag)
+	}
+	c.locales[tag] = messages
+	c.matcher = language.NewMatcher(c.tags)
+	return c
+}
+
+// Locale returns the Messages of the locale, or nil when not set
+// Locale 返回该语言环境的 Messages，未设置时返回 nil
+func (c *Catalog) Locale(tag language.Tag) *Messages {
+	return c.locales[tag]
+}
+
+// Message returns the text of the Oops in the locale closest to the preferred languages
+// Message 返回与首选语言最接近的语言环境中 Oops 的文本
+func (c *Catalog) Message(oops *Oops, preferred ...language.Tag) string {
+	_, index, _ := c.matcher.Match(preferred...)
+	return c.locales[c.tags[index]].Message(oops)
+}
+
+// MessageFor returns the text of the Oops in the locale closest to an Accept-Language header value
+// MessageFor 返回与 Accept-Language 头的值最接近的语言环境中 Oops 的文本
+func (c *Catalog) MessageFor(oops *Oops, acceptLanguage string) string {
+	preferred, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	return c.Message(oops, preferred...)
+}
+
+// defaultCatalog backs Oops.Message
+// defaultCatalog 支撑 Oops.Message
+var defaultCatalog = NewCatalog()
+
+// Message returns the end-user text of the Oops in the preferred languages, English and Chinese built in
+// Use a Catalog to customise texts or add locales
+//
+// Message 返回首选语言的 Oops 终端用户文本，内置英文和中文
+// 自定义文本或添加语言环境请使用 Catalog
+func (o *Oops) Message(preferred ...language.Tag) string {
+	return defaultCatalog.Message(o, preferred...)
+}
+
+// NewEnglishMessages creates the built-in English texts
+// NewEnglishMessages 创建内置的英文文本
+func NewEnglishMessages() *Messages {
+	return NewMessages("Something went wrong, please try again later").
+		WithKind(KindNetwork, "Network connection failed, please check your network and try again").
+		WithKind(KindHttp, "The service is unavailable, please try again later").
+		WithKind(KindParse, "The service returned an unexpected response, please try again later").
+		WithKind(KindBlock, "The request was blocked, please verify and try again").
+		WithKind(KindBusiness, "The request could not be completed").
+		WithReason(ReasonTimeout, "The request timed out, please try again later").
+		WithReason(ReasonCanceled, "The request was canceled").
+		WithReason(ReasonDNS, "The service address could not be resolved, please try again later").
+		WithReason(ReasonDNSNotFound, "The service address could not be resolved, please try again later").
+		WithStatus(400, "The request is invalid, please check and try again").
+		WithStatus(401, "Please sign in and try again").
+		WithStatus(403, "You do not have permission to do this").
+		WithStatus(404, "The requested resource was not found").
+		WithStatus(408, "The request timed out, please try again later").
+		WithStatus(409, "The resource was changed by someone else, please refresh and try again").
+		WithStatus(413, "The request is too large").
+		WithStatus(429, "Too many requests, please try again later").
+		WithStatus(500, "The service ran into a problem, please try again later").
+		WithStatus(502, "The service is busy, please try again later").
+		WithStatus(503, "The service is busy, please try again later").
+		WithStatus(504, "The service timed out, please try again later")
+}
+
+// NewChineseMessages creates the built-in Chinese texts
+// NewChineseMessages 创建内置的中文文本
+func NewChineseMessages() *Messages {
+	return NewMessages("出了点问题，请稍后重试").
+		WithKind(KindNetwork, "网络连接失败，请检查网络后重试").
+		WithKind(KindHttp, "服务暂不可用，请稍后重试").
+		WithKind(KindParse, "服务返回了异常数据，请稍后重试").
+		WithKind(KindBlock, "请求被拦截，请验证后重试").
+		WithKind(KindBusiness, "请求未能完成").
+		WithReason(ReasonTimeout, "请求超时，请稍后重试").
+		WithReason(ReasonCanceled, "请求已取消").
+		WithReason(ReasonDNS, "无法解析服务地址，请稍后重试").
+		WithReason(ReasonDNSNotFound, "无法解析服务地址，请稍后重试").
+		WithStatus(400, "请求参数有误，请检查后重试").
+		WithStatus(401, "请登录后重试").
+		WithStatus(403, "没有权限执行此操作").
+		WithStatus(404, "请求的资源不存在").
+		WithStatus(408, "请求超时，请稍后重试").
+		WithStatus(409, "资源已被修改，请刷新后重试").
+		WithStatus(413, "请求内容过大").
+		WithStatus(429, "请求过于频繁，请稍后重试").
+		WithStatus(500, "服务出现问题，请稍后重试").
+		WithStatus(502, "服务繁忙，请稍后重试").
+		WithStatus(503, "服务繁忙，请稍后重试").
+		WithStatus(504, "服务响应超时，请稍后重试")
+}
@@ -0,0 +1,52 @@
+package restyoops_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"golang.org/x/text/language"
+)
+
+// TestOops_Message tests the built-in texts by reason, status code and Kind in both languages
+// TestOops_Message 测试按原因、状态码和 Kind 的内置中英文文本
+func TestOops_Message(t *testing.T) {
+	busy := restyoops.NewOops(restyoops.KindHttp, 503, errors.New("503"), true).WithReason("service_unavailable")
+	require.Equal(t, "服务繁忙，请稍后重试", busy.Message(language.SimplifiedChinese))
+	require.Equal(t, "The service is busy, please try again later", busy.Message(language.AmericanEnglish))
+
+	timeout := restyoops.Detect(restyoops.NewConfig(), nil, context.DeadlineExceeded)
+	require.Equal(t, "请求超时，请稍后重试", timeout.Message(language.Chinese))
+
+	teapot := restyoops.NewOops(restyoops.KindHttp, 418, errors.New("418"), false)
+	require.Equal(t, "服务暂不可用，请稍后重试", teapot.Message(language.Chinese))
+
+	// Unknown languages and no preference fall back to English
+	require.Equal(t, "The service is unavailable, please try again later", teapot.Message(language.Japanese))
+	require.Equal(t, "The service is unavailable, please try again later", teapot.Message())
+}
+
+// TestCatalog tests custom texts and locales, and matching by Accept-Language
+// TestCatalog 测试自定义文本和语言环境，以及按 Accept-Language 匹配
+func TestCatalog(t *testing.T) {
+	catalog := restyoops.NewCatalog().
+		WithLocale(language.Japanese, restyoops.NewMessages("エラーが発生しました").
+			WithStatus(429, "リクエストが多すぎます"))
+	catalog.Locale(language.Chinese).WithReason("waf", "请求被安全策略拦截")
+
+	limited := restyoops.NewOops(restyoops.KindHttp, 429, errors.New("429"), true)
+	require.Equal(t, "リクエストが多すぎます", catalog.MessageFor(limited, "ja-JP,ja;q=0.9,en;q=0.8"))
+	require.Equal(t, "请求过于频繁，请稍后重试", catalog.MessageFor(limited, "zh-CN,zh;q=0.9"))
+	require.Equal(t, "Too many requests, please try again later", catalog.MessageFor(limited, "fr-FR"))
+	require.Equal(t, "Too many requests, please try again later", catalog.MessageFor(limited, "not a header"))
+
+	blocked := restyoops.NewOops(restyoops.KindBlock, 403, errors.New("403"), false).WithReason("waf")
+	require.Equal(t, "请求被安全策略拦截", catalog.Message(blocked, language.Chinese))
+	require.Equal(t, "エラーが発生しました", catalog.Message(blocked, language.Japanese))
+	require.Equal(t, "You do not have permission to do this", catalog.Message(blocked, language.English))
+
+	// The default catalog is left untouched
+	require.Equal(t, "没有权限执行此操作", blocked.Message(language.Chinese))
+}
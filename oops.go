@@ -11,11 +11,30 @@ import (
 // Oops 代表结构化的 HTTP 操作结果
 type Oops struct {
 	Kind        Kind          // Classification // 分类
+	Reason      string        // Short machine-readable reason // 简短的机器可读原因
 	StatusCode  int           // HTTP status code // HTTP 状态码
 	ContentType string        // Response Content-Type // 响应 Content-Type
 	Cause       error         // Wrapped outcome // 被包装的结果
 	Retryable   bool          // Can be resolved via retries // 是否可通过重试解决
 	WaitTime    time.Duration // Suggested wait time // 建议等待时间
+	Attempts    []*Attempt    // Retry history, oldest first // 重试历史，按时间先后
+	Exhausted   bool          // Retries given up, no more tries follow // 重试已放弃，不会再尝试
+	Source      Rule          // Rule deciding Retryable // 决定是否可重试的规则
+	Severity    Severity      // How urgent the issue is // 问题的紧急程度
+
+	BusinessCode string // Business code reported by the content check // 内容检查报告的业务码
+
+	Method     string        // Request method // 请求方法
+	URL        string        // Request URL with secrets redacted // 脱敏后的请求 URL
+	RequestID  string        // Request ID sent in configured request headers // 从配置的请求头中获取的请求 ID
+	Duration   time.Duration // Elapsed time of the request // 请求耗时
+	RemoteAddr string        // Remote address when traced // 启用追踪时的远端地址
+
+	ResponseRequestID string // Request ID returned in configured response headers // 从配置的响应头中获取的请求 ID
+
+	BodySnippet string // Capped and redacted body excerpt // 限制大小并脱敏的响应体摘录
+
+	defaultWait bool // WaitTime left to the DefaultWait of the detecting Config // WaitTime 留给检测所用 Config 的 DefaultWait
 }
 
 // IsRetryable checks if retrying is recommended
@@ -24,18 +43,37 @@ func (o *Oops) IsRetryable() bool {
 	return o.Retryable
 }
 
-// NewOops creates an Oops with the specified params
-// NewOops 使用指定的参数创建一个 Oops
+// NewOops creates an Oops with the specified params, the Kind must be built-in or registered with RegisterKind
+// NewOops 使用指定的参数创建一个 Oops，Kind 必须是内置的或已通过 RegisterKind 注册
 func NewOops(kind Kind, statusCode int, cause error, retryable bool) *Oops {
 	must.Nice(kind)
-	must.In(kind, []Kind{KindUnknown, KindNetwork, KindHttp, KindParse, KindBlock, KindBusiness})
+	must.True(kind.IsRegistered())
 	return &Oops{
 		Kind:        kind,
+		Reason:      "",
 		StatusCode:  statusCode,
 		ContentType: "",
 		Cause:       must.Cause(cause),
 		Retryable:   retryable,
 		WaitTime:    0,
+		Attempts:    nil,
+		Exhausted:   false,
+		Source:      "",
+		Severity:    "",
+
+		BusinessCode: "",
+
+		Method:     "",
+		URL:        "",
+		RequestID:  "",
+		Duration:   0,
+		RemoteAddr: "",
+
+		ResponseRequestID: "",
+
+		BodySnippet: "",
+
+		defaultWait: false,
 	}
 }
 
@@ -46,6 +84,27 @@ func (o *Oops) WithWaitTime(d time.Duration) *Oops {
 	return o
 }
 
+// WithReason sets the short machine-readable reason and returns the Oops
+// WithReason 设置简短的机器可读原因并返回 Oops
+func (o *Oops) WithReason(reason string) *Oops {
+	o.Reason = reason
+	return o
+}
+
+// WithSeverity sets the severity and returns the Oops, Detect keeps it instead of the configured one
+// WithSeverity 设置严重程度并返回 Oops，Detect 会保留它而不使用配置的严重程度
+func (o *Oops) WithSeverity(severity Severity) *Oops {
+	o.Severity = severity
+	return o
+}
+
+// WithBusinessCode sets the business code and returns the Oops
+// WithBusinessCode 设置业务码并返回 Oops
+func (o *Oops) WithBusinessCode(code string) *Oops {
+	o.BusinessCode = code
+	return o
+}
+
 // WithContentType sets the content type and returns the Oops
 // WithContentType 设置内容类型并返回 Oops
 func (o *Oops) WithContentType(contentType string) *Oops {
@@ -53,8 +112,25 @@ func (o *Oops) WithContentType(contentType string) *Oops {
 	return o
 }
 
+// WithDefaultWait leaves WaitTime to the DefaultWait of the Config used by Detect and returns the Oops
+// Checks use it so a later WithDefaultWait or Merge on the Config applies to them too
+//
+// WithDefaultWait 将 WaitTime 留给 Detect 所用 Config 的 DefaultWait 并返回 Oops
+// 检查使用它，使之后在 Config 上调用的 WithDefaultWait 或 Merge 同样对其生效
+func (o *Oops) WithDefaultWait() *Oops {
+	o.WaitTime, o.defaultWait = 0, true
+	return o
+}
+
+// WithExhausted marks the retries as given up and returns the Oops
+// WithExhausted 将重试标记为已放弃并返回 Oops
+func (o *Oops) WithExhausted() *Oops {
+	o.Exhausted = true
+	return o
+}
+
 // NewUnknown creates an Oops indicating unknown issue
 // NewUnknown 创建一个表示未知问题的 Oops
 func NewUnknown() *Oops {
-	return NewOops(KindUnknown, 0, errors.New(string(KindUnknown)), false)
+	return NewOops(KindUnknown, 0, errors.New(string(KindUnknown)), false).WithReason(ReasonUnknown)
 }
@@ -0,0 +1,212 @@
+// Package oopscheck: static analyzer catching misuse of the Detect functions
+// Reports discarded Oops results, responses used before the Oops is checked, and nil Config arguments
+//
+// oopscheck: 检查 Detect 系列函数误用的静态分析器
+// 报告被丢弃的 Oops 结果、在检查 Oops 之前使用的响应，以及传入 nil Config 的调用
+package oopscheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// modulePath is the import path prefix of the packages declaring the Detect functions
+// modulePath 是声明 Detect 系列函数的包的导入路径前缀
+const modulePath = "github.com/yyle88/restyoops"
+
+// Analyzer reports misuse of Detect, DetectHTTP, Detective.Detect and the other Detect functions
+// Analyzer 报告 Detect、DetectHTTP、Detective.Detect 等 Detect 系列函数的误用
+var Analyzer = &analysis.Analyzer{
+	Name:     "oopscheck",
+	Doc:      "report discarded Oops results, responses used before the Oops is checked and nil Config arguments of restyoops Detect functions",
+	URL:      "https://pkg.go.dev/github.com/yyle88/restyoops/oopscheck",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// run inspects each call and assignment of the package
+// run 检查包中的每个调用和赋值
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.CallExpr)(nil), (*ast.ExprStmt)(nil), (*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}
+	inspect.WithStack(nodeFilter, func(node ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			checkNilConfig(pass, node)
+		case *ast.ExprStmt:
+			if call, ok := ast.Unparen(node.X).(*ast.CallExpr); ok {
+				if fn, oopsIndex := detectFunc(pass, call); fn != nil && oopsIndex >= 0 {
+					pass.Reportf(call.Pos(), "result of %s is discarded, check the Oops before using the response", fn.Name())
+				}
+			}
+		case *ast.AssignStmt:
+			if len(node.Rhs) == 1 {
+				checkAssign(pass, node.Lhs, node.Rhs[0], node.End(), enclosingBody(stack))
+			}
+		case *ast.ValueSpec:
+			if len(node.Values) == 1 {
+				lhs := make([]ast.Expr, 0, len(node.Names))
+				for _, name := range node.Names {
+					lhs = append(lhs, name)
+				}
+				checkAssign(pass, lhs, node.Values[0], node.End(), enclosingBody(stack))
+			}
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// checkAssign reports a discarded Oops, and a response used before the Oops when both are assigned
+// checkAssign 报告被丢弃的 Oops，以及同时赋值时在 Oops 之前使用的响应
+func checkAssign(pass *analysis.Pass, lhs []ast.Expr, rhs ast.Expr, end token.Pos, body *ast.BlockStmt) {
+	call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	fn, oopsIndex := detectFunc(pass, call)
+	if fn == nil || oopsIndex < 0 || len(lhs) != fn.Signature().Results().Len() {
+		return
+	}
+	oopsIdent, ok := lhs[oopsIndex].(*ast.Ident)
+	if !ok {
+		return
+	}
+	if oopsIdent.Name == "_" {
+		pass.Reportf(oopsIdent.Pos(), "Oops result of %s is discarded", fn.Name())
+		return
+	}
+	// The response comes first when a Detect function returns both
+	// Detect 系列函数同时返回两者时响应在前
+	if oopsIndex != 1 || body == nil {
+		return
+	}
+	respIdent, ok := lhs[0].(*ast.Ident)
+	if !ok || respIdent.Name == "_" {
+		return
+	}
+	respObj, oopsObj := pass.TypesInfo.ObjectOf(respIdent), pass.TypesInfo.ObjectOf(oopsIdent)
+	if respObj == nil || oopsObj == nil {
+		return
+	}
+
+	checked := token.NoPos
+	var firstUse *ast.Ident
+	ast.Inspect(body, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok || ident.Pos() <= end {
+			return true
+		}
+		switch pass.TypesInfo.Uses[ident] {
+		case oopsObj:
+			if !checked.IsValid() || ident.Pos() < checked {
+				checked = ident.Pos()
+			}
+		case respObj:
+			if firstUse == nil || ident.Pos() < firstUse.Pos() {
+				firstUse = ident
+			}
+		}
+		return true
+	})
+	if firstUse != nil && (!checked.IsValid() || firstUse.Pos() < checked) {
+		pass.Reportf(firstUse.Pos(), "%s is used before checking the Oops %s returned by %s", respIdent.Name, oopsIdent.Name, fn.Name())
+	}
+}
+
+// checkNilConfig reports nil passed as a *Config argument of the module functions
+// checkNilConfig 报告作为模块函数 *Config 参数传入的 nil
+func checkNilConfig(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := calledFunc(pass, call)
+	if fn == nil || fn.Pkg() == nil || !inModule(fn.Pkg().Path()) {
+		return
+	}
+	params := fn.Signature().Params()
+	for idx, arg := range call.Args {
+		if idx >= params.Len() {
+			break
+		}
+		if !isNamedPointer(params.At(idx).Type(), "Config") {
+			continue
+		}
+		if tv, ok := pass.TypesInfo.Types[arg]; ok && tv.IsNil() {
+			pass.Reportf(arg.Pos(), "nil Config passed to %s, use restyoops.NewConfig()", fn.Name())
+		}
+	}
+}
+
+// detectFunc returns the called Detect function and the index of its Oops result, -1 when there is none
+// detectFunc 返回被调用的 Detect 函数及其 Oops 结果的下标，没有时返回 -1
+func detectFunc(pass *analysis.Pass, call *ast.CallExpr) (*types.Func, int) {
+	fn := calledFunc(pass, call)
+	if fn == nil || fn.Pkg() == nil || !inModule(fn.Pkg().Path()) || !strings.HasPrefix(fn.Name(), "Detect") {
+		return nil, -1
+	}
+	results := fn.Signature().Results()
+	for idx := 0; idx < results.Len(); idx++ {
+		if isNamedPointer(results.At(idx).Type(), "Oops") {
+			return fn, idx
+		}
+	}
+	return fn, -1
+}
+
+// calledFunc returns the function or method of the call, nil for calls of function values
+// calledFunc 返回调用的函数或方法，调用函数值时返回 nil
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := pass.TypesInfo.Uses[ident].(*types.Func)
+	return fn
+}
+
+// isNamedPointer checks if the type is a pointer to the named type declared in the root package
+// isNamedPointer 检查类型是否为指向根包中声明的命名类型的指针
+func isNamedPointer(typ types.Type, name string) bool {
+	pointer, ok := types.Unalias(typ).(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := types.Unalias(pointer.Elem()).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == name && obj.Pkg() != nil && obj.Pkg().Path() == modulePath
+}
+
+// inModule checks if the package path belongs to the module
+// inModule 检查包路径是否属于本模块
+func inModule(path string) bool {
+	return path == modulePath || strings.HasPrefix(path, modulePath+"/")
+}
+
+// enclosingBody returns the body of the innermost function in the stack
+// enclosingBody 返回栈中最内层函数的函数体
+func enclosingBody(stack []ast.Node) *ast.BlockStmt {
+	for idx := len(stack) - 1; idx >= 0; idx-- {
+		switch node := stack[idx].(type) {
+		case *ast.FuncDecl:
+			return node.Body
+		case *ast.FuncLit:
+			return node.Body
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package oopscheck_test
+
+import (
+	"testing"
+
+	"github.com/yyle88/restyoops/oopscheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// TestAnalyzer tests the diagnostics against the want comments of testdata/src/example
+// TestAnalyzer 测试诊断结果与 testdata/src/example 中 want 注释一致
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), oopscheck.Analyzer, "example")
+}
@@ -0,0 +1,59 @@
+package example
+
+import (
+	"errors"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/yyle88/restyoops"
+)
+
+func discarded(cfg *restyoops.Config, resp *resty.Response, err error) {
+	restyoops.Detect(cfg, resp, err)     // want `result of Detect is discarded`
+	_ = restyoops.Detect(cfg, resp, err) // want `Oops result of Detect is discarded`
+
+	detective := restyoops.NewDetective(cfg)
+	resp, _ = detective.Detect(resp, err) // want `Oops result of Detect is discarded`
+	_ = resp.Body()
+}
+
+func usedBeforeCheck(detective *restyoops.Detective, resp *resty.Response, err error) []byte {
+	resp, oops := detective.Detect(resp, err)
+	body := resp.Body() // want `resp is used before checking the Oops oops returned by Detect`
+	if oops != nil {
+		return nil
+	}
+	return body
+}
+
+func neverChecked(detective *restyoops.Detective, resp *resty.Response) int {
+	res, oops := detective.Detect(resp, errors.New("x"))
+	_ = res.StatusCode() // want `res is used before checking the Oops oops returned by Detect`
+	defer func() { _ = oops }()
+	return 0
+}
+
+func checked(detective *restyoops.Detective, resp *resty.Response, err error) []byte {
+	resp, oops := detective.Detect(resp, err)
+	if oops != nil {
+		return nil
+	}
+	return resp.Body()
+}
+
+func checkedInline(detective *restyoops.Detective, resp *resty.Response, err error) []byte {
+	if res, oops := detective.Detect(resp, err); oops == nil {
+		return res.Body()
+	}
+	var res, oops = detective.Detect(resp, err)
+	if oops != nil {
+		return nil
+	}
+	return res.Body()
+}
+
+func nilConfig(resp *resty.Response, err error) {
+	oops := restyoops.Detect(nil, resp, err) // want `nil Config passed to Detect, use restyoops.NewConfig\(\)`
+	oops.WithReason("builder results may be dropped")
+	_ = restyoops.NewDetective(nil) // want `nil Config passed to NewDetective`
+	_ = restyoops.NewDetective(restyoops.NewConfig())
+}
@@ -0,0 +1,8 @@
+// Package resty is a stub of the resty client for the analyzer tests
+package resty
+
+type Response struct{}
+
+func (r *Response) Body() []byte { return nil }
+
+func (r *Response) StatusCode() int { return 0 }
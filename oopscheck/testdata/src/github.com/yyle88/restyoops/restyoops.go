@@ -0,0 +1,24 @@
+// Package restyoops is a stub of the Detect functions for the analyzer tests
+package restyoops
+
+import "github.com/go-resty/resty/v2"
+
+type Config struct{}
+
+func NewConfig() *Config { return &Config{} }
+
+type Oops struct{ Retryable bool }
+
+type OopsIssue = Oops
+
+func (o *Oops) WithReason(reason string) *Oops { return o }
+
+func Detect(cfg *Config, resp *resty.Response, respCause error) *Oops { return nil }
+
+type Detective struct{}
+
+func NewDetective(cfg *Config) *Detective { return &Detective{} }
+
+func (c *Detective) Detect(resp *resty.Response, respCause error) (*resty.Response, *OopsIssue) {
+	return resp, nil
+}
@@ -0,0 +1,320 @@
+// Package oopsfixture: record real responses into fixture files and replay them through Detect against golden files
+// Locks down how upstream responses are classified across config changes
+//
+// oopsfixture: 将真实响应录制为 fixture 文件，并通过 Detect 回放与 golden 文件对比
+// 用于锁定上游响应在配置变更前后的分类结果
+package oopsfixture
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yyle88/restyoops"
+)
+
+// File name suffixes of fixtures and their golden files
+// fixture 文件及其 golden 文件的文件名后缀
+const (
+	FixtureSuffix = ".fixture.json"
+	GoldenSuffix  = ".golden.json"
+)
+
+// Fixture is one recorded exchange, a response or a transport failure, named by its file
+// Fixture 是一次录制的交换，可以是响应或传输失败，以文件名命名
+type Fixture struct {
+	Name     string    `json:"-"`
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response,omitempty"`
+	Failure  *Failure  `json:"failure,omitempty"`
+}
+
+// Request is the recorded request line
+// Request 是录制的请求行
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// Response is the recorded response, Body holds text and BodyBase64 holds binary content
+// Response 是录制的响应，Body 保存文本内容，BodyBase64 保存二进制内容
+type Response struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+// Failure is the recorded transport failure, Type holds the network reason like "timeout"
+// Failure 是录制的传输失败，Type 保存网络原因，如 "timeout"
+type Failure struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// NewFixture creates a Fixture of the request outcome with secrets redacted
+// Redacts the URL query values and user info, sensitive headers, and the RedactFields of the Config in JSON and form bodies
+//
+// NewFixture 根据请求结果创建已脱敏的 Fixture
+// 脱敏 URL 查询参数值和用户信息、敏感响应头，以及 JSON 和表单响应体中 Config 的 RedactFields 字段
+func NewFixture(cfg *restyoops.Config, name string, req *http.Request, resp *http.Response, content []byte, respCause error) *Fixture {
+	fixture := &Fixture{Name: name, Request: &Request{}}
+	if req != nil {
+		fixture.Request.Method = req.Method
+		fixture.Request.URL = redactURL(req.URL)
+	}
+	if respCause != nil {
+		oops := restyoops.DetectExchange(cfg, nil, respCause)
+		fixture.Failure = &Failure{Type: oops.Reason, Message: respCause.Error()}
+		return fixture
+	}
+
+	fixture.Response = &Response{StatusCode: resp.StatusCode, Header: redactHeader(resp.Header)}
+	content = restyoops.RedactBody(cfg, content)
+	if utf8.Valid(content) {
+		fixture.Response.Body = string(content)
+	} else {
+		fixture.Response.BodyBase64 = base64.StdEncoding.EncodeToString(content)
+	}
+	return fixture
+}
+
+// Exchange converts the recorded response into an Exchange, nil when a failure was recorded
+// Exchange 将录制的响应转换为 Exchange，录制的是失败时返回 nil
+func (f *Fixture) Exchange() (*restyoops.Exchange, error) {
+	if f.Response == nil {
+		return nil, nil
+	}
+	content := []byte(f.Response.Body)
+	if f.Response.BodyBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(f.Response.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: invalid body_base64: %w", f.Name, err)
+		}
+		content = decoded
+	}
+	exchange := &restyoops.Exchange{
+		StatusCode: f.Response.StatusCode,
+		Header:     f.Response.Header,
+		Body:       content,
+	}
+	if f.Request != nil {
+		exchange.Method = f.Request.Method
+		if u, err := url.Parse(f.Request.URL); err == nil {
+			exchange.URL = u
+		}
+	}
+	if exchange.Header == nil {
+		exchange.Header = http.Header{}
+	}
+	return exchange, nil
+}
+
+// Cause rebuilds an error classified like the recorded failure, nil when a response was recorded
+// Cause 重建与录制失败分类一致的错误，录制的是响应时返回 nil
+func (f *Fixture) Cause() error {
+	if f.Failure == nil {
+		return nil
+	}
+	message := f.Failure.Message
+	switch f.Failure.Type {
+	case restyoops.ReasonTimeout:
+		return &replayError{message: message, timeout: true}
+	case restyoops.ReasonCanceled:
+		return fmt.Errorf("%s: %w", message, context.Canceled)
+	case restyoops.ReasonDNS, restyoops.ReasonDNSNotFound:
+		return &net.DNSError{Err: message, IsNotFound: f.Failure.Type == restyoops.ReasonDNSNotFound}
+	case restyoops.ReasonConnection:
+		return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New(message)}
+	case restyoops.ReasonTransport:
+		urlErr := &url.Error{Op: "Get", Err: errors.New(message)}
+		if f.Request != nil {
+			urlErr.Op, urlErr.URL = f.Request.Method, f.Request.URL
+		}
+		return urlErr
+	case restyoops.ReasonNetwork:
+		return &replayError{message: message, timeout: false}
+	default:
+		return errors.New(message)
+	}
+}
+
+// Detect replays the fixture through DetectExchange with the Config
+// Detect 使用 Config 通过 DetectExchange 回放 fixture
+func (f *Fixture) Detect(cfg *restyoops.Config) (*restyoops.Oops, error) {
+	exchange, err := f.Exchange()
+	if err != nil {
+		return nil, err
+	}
+	return restyoops.DetectExchange(cfg, exchange, f.Cause()), nil
+}
+
+// replayError is a net.Error rebuilt from a recorded failure
+// replayError 是根据录制失败重建的 net.Error
+type replayError struct {
+	message string
+	timeout bool
+}
+
+// Error returns the recorded message
+// Error 返回录制的消息
+func (e *replayError) Error() string { return e.message }
+
+// Timeout reports whether the recorded failure was a timeout
+// Timeout 报告录制的失败是否为超时
+func (e *replayError) Timeout() bool { return e.timeout }
+
+// Temporary reports false, kept to implement net.Error
+// Temporary 返回 false，用于实现 net.Error
+func (e *replayError) Temporary() bool { return false }
+
+// Golden is the expected classification of a fixture
+// Golden 是 fixture 的预期分类结果
+type Golden struct {
+	Success    bool   `json:"success,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	StatusCode int    `json:"status,omitempty"`
+	Retryable  bool   `json:"retryable"`
+	Wait       string `json:"wait,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Source     string `json:"source,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+}
+
+// NewGolden creates the Golden of the Oops, nil Oops means success
+// NewGolden 根据 Oops 创建 Golden，Oops 为 nil 表示成功
+func NewGolden(oops *restyoops.Oops) *Golden {
+	if oops == nil {
+		return &Golden{Success: true}
+	}
+	return &Golden{
+		Kind:       oops.Kind.String(),
+		StatusCode: oops.StatusCode,
+		Retryable:  oops.Retryable,
+		Wait:       oops.WaitTime.String(),
+		Reason:     oops.Reason,
+		Source:     string(oops.Source),
+		Severity:   oops.Severity.String(),
+	}
+}
+
+// Load reads a fixture file
+// Load 读取 fixture 文件
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", path, err)
+	}
+	if (fixture.Response == nil) == (fixture.Failure == nil) {
+		return nil, fmt.Errorf("fixture %s: exactly one of response and failure is required", path)
+	}
+	fixture.Name = strings.TrimSuffix(filepath.Base(path), FixtureSuffix)
+	return &fixture, nil
+}
+
+// LoadDir reads the fixture files of the directory, sorted by name
+// LoadDir 读取目录中的 fixture 文件，按名称排序
+func LoadDir(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+FixtureSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		fixture, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// Save writes the fixture into the directory as <name>.fixture.json
+// Save 将 fixture 写入目录，文件名为 <name>.fixture.json
+func Save(dir string, fixture *Fixture) error {
+	return writeJSON(filepath.Join(dir, fixture.Name+FixtureSuffix), fixture)
+}
+
+// SaveGolden writes the golden file of the fixture into the directory as <name>.golden.json
+// SaveGolden 将 fixture 的 golden 文件写入目录，文件名为 <name>.golden.json
+func SaveGolden(dir string, name string, golden *Golden) error {
+	return writeJSON(filepath.Join(dir, name+GoldenSuffix), golden)
+}
+
+// LoadGolden reads the golden file of the fixture from the directory
+// LoadGolden 从目录中读取 fixture 的 golden 文件
+func LoadGolden(dir string, name string) (*Golden, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name+GoldenSuffix))
+	if err != nil {
+		return nil, err
+	}
+	var golden Golden
+	if err := json.Unmarshal(data, &golden); err != nil {
+		return nil, fmt.Errorf("golden %s: %w", name, err)
+	}
+	return &golden, nil
+}
+
+// writeJSON writes the value as indented JSON ending with a newline
+// writeJSON 将值写为带缩进并以换行结尾的 JSON
+func writeJSON(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// sensitiveHeaderWords are the words marking a header as sensitive, like "Authorization", "Set-Cookie" and "X-Api-Key"
+// sensitiveHeaderWords 是标记响应头为敏感的词，如 "Authorization"、"Set-Cookie" 和 "X-Api-Key"
+var sensitiveHeaderWords = []string{"auth", "cookie", "key", "password", "secret", "session", "signature", "token"}
+
+// redactHeader returns a copy of the header with the values of sensitive headers redacted
+// redactHeader 返回响应头的拷贝，其中敏感响应头的值已脱敏
+func redactHeader(header http.Header) http.Header {
+	res := header.Clone()
+	for name := range res {
+		lower := strings.ToLower(name)
+		for _, word := range sensitiveHeaderWords {
+			if strings.Contains(lower, word) {
+				res[name] = []string{"REDACTED"}
+				break
+			}
+		}
+	}
+	return res
+}
+
+// redactURL hides the user info and all query values of the URL
+// redactURL 隐藏 URL 中的用户信息和所有查询参数值
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	clone := *u
+	clone.User = nil
+	if clone.RawQuery != "" {
+		query := clone.Query()
+		for key := range query {
+			query[key] = []string{"REDACTED"}
+		}
+		clone.RawQuery = query.Encode()
+	}
+	return clone.String()
+}
@@ -0,0 +1,136 @@
+package oopsfixture
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yyle88/must"
+	"github.com/yyle88/restyoops"
+)
+
+// Recorder is an http.RoundTripper saving each exchange as a fixture file, safe in concurrent use
+// Recorder 是将每次交换保存为 fixture 文件的 http.RoundTripper，可安全并发使用
+type Recorder struct {
+	base  http.RoundTripper
+	cfg   *restyoops.Config
+	dir   string
+	mutex sync.Mutex
+	count int
+	errs  []error
+}
+
+// NewRecorder creates a Recorder over http.DefaultTransport, saving fixtures into the directory
+// Numbering continues after the highest number in the directory, so no fixture is overwritten
+//
+// NewRecorder 创建基于 http.DefaultTransport 的 Recorder，将 fixture 保存到目录中
+// 编号接续目录中最大的编号，因此不会覆盖已有的 fixture
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{base: http.DefaultTransport, cfg: restyoops.NewConfig(), dir: dir, count: lastNumber(dir)}
+}
+
+// WithBase sets the underlying RoundTripper
+// WithBase 设置底层的 RoundTripper
+func (r *Recorder) WithBase(base http.RoundTripper) *Recorder {
+	must.True(base != nil)
+	r.base = base
+	return r
+}
+
+// WithConfig sets the Config whose RedactFields are redacted in recorded bodies, and whose BodyLimit caps them
+// WithConfig 设置 Config，录制的响应体中会脱敏其 RedactFields 字段，并以其 BodyLimit 限制大小
+func (r *Recorder) WithConfig(cfg *restyoops.Config) *Recorder {
+	r.cfg = must.Full(cfg)
+	return r
+}
+
+// RoundTrip sends the request and saves the outcome, the response body stays fully readable
+// At most BodyLimit bytes of the body are recorded (DefaultBodyLimit when not positive), the rest stays in the stream
+//
+// RoundTrip 发送请求并保存结果，响应体仍可完整读取
+// 最多录制响应体的 BodyLimit 字节（非正数时为 DefaultBodyLimit），其余部分保留在流中
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, respCause := r.base.RoundTrip(req)
+	var content []byte
+	if resp != nil && resp.Body != nil {
+		var readErr error
+		content, readErr = io.ReadAll(io.LimitReader(resp.Body, r.bodyLimit()))
+		if readErr != nil {
+			_ = resp.Body.Close()
+			return nil, readErr
+		}
+		resp.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(content), resp.Body), Closer: resp.Body}
+	}
+
+	name := r.nextName(req, resp, respCause)
+	if err := Save(r.dir, NewFixture(r.cfg, name, req, resp, content, respCause)); err != nil {
+		r.mutex.Lock()
+		r.errs = append(r.errs, err)
+		r.mutex.Unlock()
+	}
+	return resp, respCause
+}
+
+// bodyLimit returns the max bytes recorded from the body, falling back to DefaultBodyLimit when not positive
+// bodyLimit 返回录制响应体的最大字节数，非正数时回退到 DefaultBodyLimit
+func (r *Recorder) bodyLimit() int64 {
+	if r.cfg.BodyLimit <= 0 {
+		return restyoops.DefaultBodyLimit
+	}
+	return int64(r.cfg.BodyLimit)
+}
+
+// replayBody reads the recorded part and then the rest of the body, closing the original body
+// replayBody 先读取已录制的部分再读取剩余响应体，关闭时关闭原始响应体
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// Errs returns the failures of saving fixtures
+// Errs 返回保存 fixture 时的失败
+func (r *Recorder) Errs() []error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+// unsafeNameChars matches characters replaced in fixture names
+// unsafeNameChars 匹配 fixture 名称中需要替换的字符
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// nextName names the fixture like "001-get-api-example-com-503"
+// nextName 为 fixture 命名，如 "001-get-api-example-com-503"
+func (r *Recorder) nextName(req *http.Request, resp *http.Response, respCause error) string {
+	r.mutex.Lock()
+	r.count++
+	count := r.count
+	r.mutex.Unlock()
+
+	outcome := "failure"
+	if respCause == nil && resp != nil {
+		outcome = strconv.Itoa(resp.StatusCode)
+	}
+	host := unsafeNameChars.ReplaceAllString(req.URL.Hostname(), "-")
+	return strings.ToLower(fmt.Sprintf("%03d-%s-%s-%s", count, req.Method, host, outcome))
+}
+
+// lastNumber returns the highest number prefixing the fixture names in the directory, 0 when none
+// lastNumber 返回目录中 fixture 名称前缀的最大编号，没有时返回 0
+func lastNumber(dir string) int {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*"+FixtureSuffix))
+	res := 0
+	for _, path := range paths {
+		prefix, _, _ := strings.Cut(filepath.Base(path), "-")
+		if number, err := strconv.Atoi(prefix); err == nil && number > res {
+			res = number
+		}
+	}
+	return res
+}
@@ -0,0 +1,51 @@
+package oopsfixture
+
+import (
+	"os"
+	"testing"
+
+	"github.com/yyle88/restyoops"
+)
+
+// UpdateEnv is the environment variable, set to "1" to rewrite golden files instead of comparing
+// UpdateEnv 是环境变量，设为 "1" 时重写 golden 文件而不是对比
+const UpdateEnv = "RESTYOOPS_UPDATE_GOLDEN"
+
+// Verify replays each fixture of the directory through the Config and compares with its golden file
+// Missing golden files fail the test, run with RESTYOOPS_UPDATE_GOLDEN=1 to write them
+//
+// Verify 使用 Config 回放目录中的每个 fixture，并与其 golden 文件对比
+// 缺少 golden 文件会使测试失败，使用 RESTYOOPS_UPDATE_GOLDEN=1 运行可写入 golden 文件
+func Verify(t *testing.T, cfg *restyoops.Config, dir string) {
+	t.Helper()
+	fixtures, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("load fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("no fixtures in %s", dir)
+	}
+	update := os.Getenv(UpdateEnv) == "1"
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			oops, err := fixture.Detect(cfg)
+			if err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			actual := NewGolden(oops)
+			if update {
+				if err := SaveGolden(dir, fixture.Name, actual); err != nil {
+					t.Fatalf("save golden: %v", err)
+				}
+				return
+			}
+			expected, err := LoadGolden(dir, fixture.Name)
+			if err != nil {
+				t.Fatalf("load golden: %v (run with %s=1 to write it)", err, UpdateEnv)
+			}
+			if *expected != *actual {
+				t.Errorf("classification changed\nexpected: %+v\nactual:   %+v", *expected, *actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Package oopsmetrics: Prometheus metrics on restyoops classified outcomes
+// Exports counters and histograms labelled by host, method, Kind, status class, retryable and severity
+//
+// oopsmetrics: 基于 restyoops 分类结果的 Prometheus 指标
+// 导出按主机、方法、Kind、状态码类别、可重试标记和严重程度的计数器和直方图
+package oopsmetrics
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yyle88/must"
+	"github.com/yyle88/restyoops"
+)
+
+// Label values used when the real value is hidden or missing
+// 真实值被隐藏或缺失时使用的标签值
+const (
+	KindSuccess = "OK"    // kind label of success outcomes // 成功结果的 kind 标签
+	OtherLabel  = "other" // host or route beyond cardinality limits // 超出基数限制的主机或路由
+	NoneLabel   = "none"  // missing host or status // 缺失的主机或状态码
+)
+
+// Options holds metric naming and cardinality settings
+// Options 保存指标命名和基数设置
+type Options struct {
+	Namespace    string                            // metric name prefix // 指标名前缀
+	WaitBuckets  []float64                         // buckets of wait seconds // 等待秒数的桶
+	AllowedHosts []string                          // hosts kept as labels, others become "other" // 保留为标签的主机，其余为 "other"
+	MaxHosts     int                               // max distinct hosts when AllowedHosts is empty // AllowedHosts 为空时的最大主机数
+	MaxRoutes    int                               // max distinct routes // 最大路由数
+	RouteLabel   func(oops *restyoops.Oops) string // route label, nil omits the label // 路由标签，nil 表示不使用该标签
+}
+
+// NewOptions creates Options with sensible defaults
+// NewOptions 创建带有合理默认值的 Options
+func NewOptions() *Options {
+	return &Options{
+		Namespace:    "restyoops",
+		WaitBuckets:  []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60},
+		AllowedHosts: nil,
+		MaxHosts:     50,
+		MaxRoutes:    100,
+		RouteLabel:   nil,
+	}
+}
+
+// WithAllowedHosts sets the hosts kept as labels, others become "other"
+// WithAllowedHosts 设置保留为标签的主机，其余为 "other"
+func (o *Options) WithAllowedHosts(hosts ...string) *Options {
+	o.AllowedHosts = hosts
+	return o
+}
+
+// WithMaxHosts sets the max distinct hosts kept as labels, others become "other"
+// WithMaxHosts 设置保留为标签的最大主机数，其余为 "other"
+func (o *Options) WithMaxHosts(maxHosts int) *Options {
+	o.MaxHosts = maxHosts
+	return o
+}
+
+// WithRouteLabel adds a route label computed from the Oops, capped at maxRoutes distinct values
+// WithRouteLabel 添加由 Oops 计算的路由标签，最多 maxRoutes 个不同值
+func (o *Options) WithRouteLabel(routeLabel func(oops *restyoops.Oops) string, maxRoutes int) *Options {
+	o.RouteLabel = routeLabel
+	o.MaxRoutes = maxRoutes
+	return o
+}
+
+// Metrics holds the Prometheus collectors of classified outcomes
+// Metrics 保存分类结果的 Prometheus 收集器
+type Metrics struct {
+	opts     *Options
+	outcomes *prometheus.CounterVec
+	waits    *prometheus.HistogramVec
+	hosts    *limiter
+	routes   *limiter
+}
+
+// New creates Metrics with the Options, register them via Register
+// New 使用 Options 创建 Metrics，通过 Register 注册
+func New(opts *Options) *Metrics {
+	must.Full(opts)
+	outcomeLabels := []string{"host", "method", "kind", "status_class", "retryable", "severity"}
+	waitLabels := []string{"host", "method", "kind"}
+	if opts.RouteLabel != nil {
+		outcomeLabels = append(outcomeLabels, "route")
+		waitLabels = append(waitLabels, "route")
+	}
+	return &Metrics{
+		opts: opts,
+		outcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "outcomes_total",
+			Help:      "Count of HTTP outcomes classified by restyoops.",
+		}, outcomeLabels),
+		waits: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "retry_wait_seconds",
+			Help:      "Suggested wait before retrying retryable outcomes.",
+			Buckets:   opts.WaitBuckets,
+		}, waitLabels),
+		hosts:  newLimiter(opts.AllowedHosts, opts.MaxHosts),
+		routes: newLimiter(nil, opts.MaxRoutes),
+	}
+}
+
+// Register registers the collectors with the registerer
+// Register 将收集器注册到 registerer
+func (m *Metrics) Register(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{m.outcomes, m.waits} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Observe records a classified failure
+// Observe 记录一个已分类的失败
+func (m *Metrics) Observe(oops *restyoops.Oops) {
+	if oops == nil {
+		return
+	}
+	host := m.hosts.label(hostOf(oops.URL))
+	method := methodLabel(oops.Method)
+	kind := oops.Kind.String()
+
+	outcomeValues := []string{host, method, kind, statusClass(oops.StatusCode), strconv.FormatBool(oops.Retryable), severityLabel(oops.Severity)}
+	waitValues := []string{host, method, kind}
+	if m.opts.RouteLabel != nil {
+		route := m.routes.label(m.opts.RouteLabel(oops))
+		outcomeValues = append(outcomeValues, route)
+		waitValues = append(waitValues, route)
+	}
+	m.outcomes.WithLabelValues(outcomeValues...).Inc()
+	if oops.Retryable {
+		m.waits.WithLabelValues(waitValues...).Observe(oops.WaitTime.Seconds())
+	}
+}
+
+// ObserveSuccess records a success outcome of the response
+// ObserveSuccess 记录响应的成功结果
+func (m *Metrics) ObserveSuccess(resp *resty.Response) {
+	must.Full(resp)
+	var rawURL, method string
+	if req := resp.Request; req != nil {
+		method = req.Method
+		rawURL = req.URL
+		if req.RawRequest != nil && req.RawRequest.URL != nil {
+			rawURL = req.RawRequest.URL.String()
+		}
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		u.User = nil
+		u.RawQuery = "" // keep secrets away from route labels
+		rawURL = u.String()
+	}
+
+	values := []string{m.hosts.label(hostOf(rawURL)), methodLabel(method), KindSuccess, statusClass(resp.StatusCode()), "false", NoneLabel}
+	if m.opts.RouteLabel != nil {
+		oops := &restyoops.Oops{Method: method, URL: rawURL, StatusCode: resp.StatusCode()}
+		values = append(values, m.routes.label(m.opts.RouteLabel(oops)))
+	}
+	m.outcomes.WithLabelValues(values...).Inc()
+}
+
+// NewHook creates a restyoops.Hook observing each failure
+// NewHook 创建观察每个失败的 restyoops.Hook
+func (m *Metrics) NewHook() restyoops.Hook {
+	return func(_ context.Context, oops *restyoops.Oops) {
+		m.Observe(oops)
+	}
+}
+
+// Instrument wires the metrics into the resty client, counting both failures and successes
+// Each response is classified once, through AttachWithSuccess
+//
+// Instrument 将指标接入 resty 客户端，同时统计失败和成功
+// 每个响应通过 AttachWithSuccess 只分类一次
+func (m *Metrics) Instrument(detective *restyoops.Detective, client *resty.Client) *resty.Client {
+	must.Full(detective)
+	return detective.AttachWithSuccess(client, m.ObserveSuccess, m.NewHook())
+}
+
+// limiter caps the count of distinct label values
+// limiter 限制不同标签值的数量
+type limiter struct {
+	mutex   sync.Mutex
+	allowed map[string]struct{}
+	fixed   bool
+	limit   int
+}
+
+// newLimiter creates a limiter, a fixed allow-list takes precedence over the limit
+// newLimiter 创建 limiter，固定的允许列表优先于数量限制
+func newLimiter(allowed []string, limit int) *limiter {
+	res := &limiter{
+		allowed: make(map[string]struct{}, len(allowed)),
+		fixed:   len(allowed) > 0,
+		limit:   limit,
+	}
+	for _, value := range allowed {
+		res.allowed[value] = struct{}{}
+	}
+	return res
+}
+
+// label returns the value when admitted, "other" when beyond limits
+// label 在允许时返回原值，超出限制时返回 "other"
+func (l *limiter) label(value string) string {
+	if value == "" {
+		return NoneLabel
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, ok := l.allowed[value]; ok {
+		return value
+	}
+	if l.fixed || len(l.allowed) >= l.limit {
+		return OtherLabel
+	}
+	l.allowed[value] = struct{}{}
+	return value
+}
+
+// hostOf extracts the host name from the URL
+// hostOf 从 URL 中提取主机名
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// methodLabel returns the method, or "none" when missing
+// methodLabel 返回请求方法，缺失时返回 "none"
+func methodLabel(method string) string {
+	if method == "" {
+		return NoneLabel
+	}
+	return method
+}
+
+// statusClass converts the status code into a class like "5xx"
+// statusClass 将状态码转换为类别，如 "5xx"
+func statusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 999 {
+		return NoneLabel
+	}
+	return strconv.Itoa(statusCode/100) + "xx"
+}
+
+// severityLabel returns the severity label, "none" when not set
+// severityLabel 返回严重程度标签，未设置时为 "none"
+func severityLabel(severity restyoops.Severity) string {
+	if severity == "" {
+		return NoneLabel
+	}
+	return severity.String()
+}
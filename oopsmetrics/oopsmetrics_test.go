@@ -0,0 +1,83 @@
+package oopsmetrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/oopsmetrics"
+)
+
+// TestMetrics_Instrument tests outcomes and waits are counted with labels
+// TestMetrics_Instrument 测试按标签统计结果和等待时间
+func TestMetrics_Instrument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/busy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	metrics := oopsmetrics.New(oopsmetrics.NewOptions())
+	require.NoError(t, metrics.Register(registry))
+
+	detective := restyoops.NewDetective(restyoops.NewConfig().WithDefaultWait(2 * time.Second))
+	client := metrics.Instrument(detective, resty.New())
+
+	for _, path := range []string{"/ok", "/busy", "/busy"} {
+		_, err := client.R().Get(server.URL + path)
+		require.NoError(t, err)
+	}
+
+	expected := `
+# HELP restyoops_outcomes_total Count of HTTP outcomes classified by restyoops.
+# TYPE restyoops_outcomes_total counter
+restyoops_outcomes_total{host="127.0.0.1",kind="HTTP",method="GET",retryable="true",severity="warn",status_class="5xx"} 2
+restyoops_outcomes_total{host="127.0.0.1",kind="OK",method="GET",retryable="false",severity="none",status_class="2xx"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "restyoops_outcomes_total"))
+	require.Equal(t, 1, testutil.CollectAndCount(registry, "restyoops_retry_wait_seconds"))
+}
+
+// TestMetrics_Cardinality tests hosts and routes beyond limits collapse into "other"
+// TestMetrics_Cardinality 测试超出限制的主机和路由合并为 "other"
+func TestMetrics_Cardinality(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	opts := oopsmetrics.NewOptions().
+		WithAllowedHosts("api.example.com").
+		WithRouteLabel(func(oops *restyoops.Oops) string { return oops.Method + " " + oops.URL }, 1)
+	metrics := oopsmetrics.New(opts)
+	require.NoError(t, metrics.Register(registry))
+
+	newOops := func(rawURL string) *restyoops.Oops {
+		oops := restyoops.NewOops(restyoops.KindBlock, http.StatusForbidden, errors.New("waf"), false)
+		oops.Method = http.MethodGet
+		oops.URL = rawURL
+		return oops
+	}
+	metrics.Observe(newOops("https://api.example.com/a"))
+	metrics.Observe(newOops("https://api.example.com/b"))
+	metrics.Observe(newOops("https://cdn.example.com/a"))
+	metrics.Observe(nil)
+
+	expected := `
+# HELP restyoops_outcomes_total Count of HTTP outcomes classified by restyoops.
+# TYPE restyoops_outcomes_total counter
+restyoops_outcomes_total{host="api.example.com",kind="BLOCK",method="GET",retryable="false",route="GET https://api.example.com/a",severity="none",status_class="4xx"} 1
+restyoops_outcomes_total{host="api.example.com",kind="BLOCK",method="GET",retryable="false",route="other",severity="none",status_class="4xx"} 1
+restyoops_outcomes_total{host="other",kind="BLOCK",method="GET",retryable="false",route="other",severity="none",status_class="4xx"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "restyoops_outcomes_total"))
+	require.Equal(t, 0, testutil.CollectAndCount(registry, "restyoops_retry_wait_seconds"))
+}
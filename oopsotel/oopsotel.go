@@ -0,0 +1,152 @@
+// Package oopsotel: OpenTelemetry span enrichment with restyoops classification
+// Records the final Oops onto the active span, adds a span event per retry, and sets span status per Kind
+//
+// oopsotel: 使用 restyoops 分类信息丰富 OpenTelemetry span
+// 将最终的 Oops 记录到当前 span，每次重试添加 span 事件，并按 Kind 设置 span 状态
+package oopsotel
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/yyle88/must"
+	"github.com/yyle88/restyoops"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys recorded on spans and events
+// 记录在 span 和事件上的属性键
+const (
+	KeyErrorType   = attribute.Key("error.type")
+	KeyStatusCode  = attribute.Key("http.response.status_code")
+	KeyKind        = attribute.Key("restyoops.kind")
+	KeyReason      = attribute.Key("restyoops.reason")
+	KeyRetryable   = attribute.Key("restyoops.retryable")
+	KeyWaitMs      = attribute.Key("restyoops.wait_ms")
+	KeySeverity    = attribute.Key("restyoops.severity")
+	KeyFingerprint = attribute.Key("restyoops.fingerprint")
+	KeyAttempt     = attribute.Key("restyoops.attempt")
+)
+
+// RetryEventName is the name of the span event added before each retry
+// RetryEventName 是每次重试前添加的 span 事件名称
+const RetryEventName = "restyoops.retry"
+
+// Options holds span status settings
+// Options 保存 span 状态设置
+type Options struct {
+	StatusCodes map[restyoops.Kind]codes.Code // span status per Kind // 各 Kind 的 span 状态
+	DefaultCode codes.Code                    // span status when Kind not set // Kind 未设置时的 span 状态
+}
+
+// NewOptions creates Options with sensible defaults, business issues leave the span status unset
+// NewOptions 创建带有合理默认值的 Options，业务问题不设置 span 状态
+func NewOptions() *Options {
+	return &Options{
+		StatusCodes: map[restyoops.Kind]codes.Code{
+			restyoops.KindBusiness: codes.Unset,
+		},
+		DefaultCode: codes.Error,
+	}
+}
+
+// WithStatusCode sets the span status used with the Kind
+// WithStatusCode 设置该 Kind 使用的 span 状态
+func (o *Options) WithStatusCode(kind restyoops.Kind, code codes.Code) *Options {
+	o.StatusCodes[kind] = code
+	return o
+}
+
+// RecordOops records the Oops classification onto the span and sets span status per Kind
+// RecordOops 将 Oops 分类记录到 span 上，并按 Kind 设置 span 状态
+func RecordOops(span trace.Span, oops *restyoops.Oops, opts *Options) {
+	must.Full(opts)
+	if oops == nil || !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(oopsAttributes(oops)...)
+
+	code := opts.DefaultCode
+	if kindCode, ok := opts.StatusCodes[oops.Kind]; ok {
+		code = kindCode
+	}
+	if code == codes.Error {
+		span.RecordError(oops.Cause)
+		span.SetStatus(codes.Error, errorType(oops))
+	} else if code == codes.Ok {
+		span.SetStatus(codes.Ok, "")
+	}
+}
+
+// NewHook creates a restyoops.Hook recording final failures onto the span in the request context
+// Failures followed by a retry are skipped, Instrument shows them as retry events
+//
+// NewHook 创建 restyoops.Hook，将最终失败记录到请求上下文中的 span
+// 之后会重试的失败会被跳过，Instrument 将它们显示为重试事件
+func NewHook(opts *Options) restyoops.Hook {
+	must.Full(opts)
+	return func(ctx context.Context, oops *restyoops.Oops) {
+		if !restyoops.ShouldReport(oops) {
+			return
+		}
+		RecordOops(trace.SpanFromContext(ctx), oops, opts)
+	}
+}
+
+// Instrument wires span enrichment and retry events into the resty client
+// Instrument 将 span 丰富和重试事件接入 resty 客户端
+func Instrument(detective *restyoops.Detective, client *resty.Client, opts *Options) *resty.Client {
+	must.Full(detective)
+	detective.Attach(client, NewHook(opts))
+	client.AddRetryHook(func(resp *resty.Response, respCause error) {
+		if resp == nil || resp.Request == nil {
+			return
+		}
+		if resp.Request.Attempt > client.RetryCount {
+			return // resty runs retry hooks after the last attempt too, though no retry follows
+		}
+		span := trace.SpanFromContext(resp.Request.Context())
+		if !span.IsRecording() {
+			return
+		}
+		attrs := []attribute.KeyValue{KeyAttempt.Int(resp.Request.Attempt)}
+		if _, oops := detective.Detect(resp, respCause); oops != nil {
+			attrs = append(attrs, oopsAttributes(oops)...)
+		}
+		span.AddEvent(RetryEventName, trace.WithAttributes(attrs...))
+	})
+	return client
+}
+
+// oopsAttributes converts the Oops into span attributes
+// oopsAttributes 将 Oops 转换为 span 属性
+func oopsAttributes(oops *restyoops.Oops) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		KeyErrorType.String(errorType(oops)),
+		KeyKind.String(oops.Kind.String()),
+		KeyRetryable.Bool(oops.Retryable),
+		KeyWaitMs.Int64(oops.WaitTime.Milliseconds()),
+		KeyFingerprint.String(oops.Fingerprint()),
+	}
+	if oops.Reason != "" {
+		attrs = append(attrs, KeyReason.String(oops.Reason))
+	}
+	if oops.StatusCode > 0 {
+		attrs = append(attrs, KeyStatusCode.Int(oops.StatusCode))
+	}
+	if oops.Severity != "" {
+		attrs = append(attrs, KeySeverity.String(oops.Severity.String()))
+	}
+	return attrs
+}
+
+// errorType returns the reason, or the Kind when no reason set
+// errorType 返回原因，未设置原因时返回 Kind
+func errorType(oops *restyoops.Oops) string {
+	if oops.Reason != "" {
+		return oops.Reason
+	}
+	return oops.Kind.String()
+}
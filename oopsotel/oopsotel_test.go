@@ -0,0 +1,142 @@
+package oopsotel_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/oopsotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTracer creates a tracer recording spans into memory
+// newTracer 创建将 span 记录到内存的 tracer
+func newTracer() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+// attrValue finds the attribute value with the key
+// attrValue 查找指定键的属性值
+func attrValue(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// TestInstrument tests span attributes, retry events and span status on retried 503
+// TestInstrument 测试重试 503 时的 span 属性、重试事件和 span 状态
+func TestInstrument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider, recorder := newTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "call")
+
+	client := resty.New().
+		SetRetryCount(2).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			return resp.StatusCode() == http.StatusServiceUnavailable
+		})
+	detective := restyoops.NewDetective(restyoops.NewConfig().WithStatusRetryable(503, true, 3*time.Second))
+	oopsotel.Instrument(detective, client, oopsotel.NewOptions())
+
+	_, err := client.R().SetContext(ctx).Get(server.URL)
+	require.NoError(t, err)
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	got := spans[0]
+	require.Equal(t, codes.Error, got.Status().Code)
+	require.Equal(t, "service_unavailable", got.Status().Description)
+	require.Equal(t, "HTTP", attrValue(got.Attributes(), oopsotel.KeyKind).AsString())
+	require.Equal(t, "service_unavailable", attrValue(got.Attributes(), oopsotel.KeyErrorType).AsString())
+	require.Equal(t, int64(503), attrValue(got.Attributes(), oopsotel.KeyStatusCode).AsInt64())
+	require.True(t, attrValue(got.Attributes(), oopsotel.KeyRetryable).AsBool())
+
+	var retries []sdktrace.Event
+	for _, event := range got.Events() {
+		if event.Name == oopsotel.RetryEventName {
+			retries = append(retries, event)
+		}
+	}
+	require.Len(t, retries, 2)
+	require.Equal(t, int64(1), attrValue(retries[0].Attributes, oopsotel.KeyAttempt).AsInt64())
+	require.Equal(t, int64(3000), attrValue(retries[0].Attributes, oopsotel.KeyWaitMs).AsInt64())
+}
+
+// TestInstrument_RetrySucceeds tests a retried request ending in success leaves the span status unset
+// TestInstrument_RetrySucceeds 测试重试后成功的请求不设置 span 状态
+func TestInstrument_RetrySucceeds(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	provider, recorder := newTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "call")
+
+	client := resty.New().
+		SetRetryCount(2).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			return resp.StatusCode() == http.StatusServiceUnavailable
+		})
+	oopsotel.Instrument(restyoops.NewDetective(restyoops.NewConfig()), client, oopsotel.NewOptions())
+
+	resp, err := client.R().SetContext(ctx).Get(server.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	got := spans[0]
+	require.Equal(t, codes.Unset, got.Status().Code)
+	require.Empty(t, attrValue(got.Attributes(), oopsotel.KeyErrorType).AsString())
+	require.Len(t, got.Events(), 1)
+	require.Equal(t, oopsotel.RetryEventName, got.Events()[0].Name)
+}
+
+// TestRecordOops_StatusPerKind tests span status follows the Kind setting
+// TestRecordOops_StatusPerKind 测试 span 状态遵循 Kind 设置
+func TestRecordOops_StatusPerKind(t *testing.T) {
+	provider, recorder := newTracer()
+	tracer := provider.Tracer("test")
+	opts := oopsotel.NewOptions().WithStatusCode(restyoops.KindBlock, codes.Ok)
+
+	_, span := tracer.Start(context.Background(), "business")
+	oopsotel.RecordOops(span, restyoops.NewOops(restyoops.KindBusiness, 200, errors.New("code=1001"), false), opts)
+	span.End()
+
+	_, span = tracer.Start(context.Background(), "block")
+	oopsotel.RecordOops(span, restyoops.NewOops(restyoops.KindBlock, 403, errors.New("waf"), false), opts)
+	span.End()
+
+	spans := recorder.Ended()
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, "BUSINESS", attrValue(spans[0].Attributes(), oopsotel.KeyErrorType).AsString())
+	require.Equal(t, codes.Ok, spans[1].Status().Code)
+}
@@ -0,0 +1,461 @@
+// Package oopspreset: curated restyoops Config presets of well-known APIs and gateways
+// Each preset is a fresh Config, use it as is or stack several via Combine
+//
+// oopspreset: 常见 API 和网关的 restyoops Config 预设
+// 每个预设都是新的 Config，可以直接使用，也可以通过 Combine 叠加多个
+package oopspreset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yyle88/restyoops"
+)
+
+// Reasons reported by the presets
+// 预设报告的原因
+const (
+	ReasonThrottling         = "throttling"           // AWS throttling error code // AWS 限流错误码
+	ReasonTransient          = "transient"            // AWS transient error code // AWS 瞬时错误码
+	ReasonRateLimited        = "rate_limited"         // primary rate limit exhausted // 主速率限制耗尽
+	ReasonSecondaryRateLimit = "secondary_rate_limit" // GitHub secondary rate limit // GitHub 次级速率限制
+	ReasonShouldRetry        = "should_retry"         // Stripe-Should-Retry: true // Stripe-Should-Retry: true
+	ReasonShouldNotRetry     = "should_not_retry"     // Stripe-Should-Retry: false // Stripe-Should-Retry: false
+	ReasonChallenge          = "challenge"            // Cloudflare challenge page // Cloudflare 质询页面
+)
+
+// AWS creates a Config recognizing AWS throttling and transient error codes
+// The code is read from the X-Amzn-ErrorType header, JSON "__type" / "code" fields, or the XML <Code> element
+//
+// AWS 创建识别 AWS 限流和瞬时错误码的 Config
+// 错误码从 X-Amzn-ErrorType 头、JSON 的 "__type" / "code" 字段或 XML 的 <Code> 元素中读取
+func AWS() *restyoops.Config {
+	cfg := restyoops.NewConfig()
+	check := func(header http.Header, content []byte) *restyoops.Oops {
+		code := awsErrorCode(header, content)
+		var reason string
+		switch {
+		case awsThrottlingCodes[code]:
+			reason = ReasonThrottling
+		case awsTransientCodes[code]:
+			reason = ReasonTransient
+		default:
+			return nil
+		}
+		cause := fmt.Errorf("aws error code %s", code)
+		return newOops(restyoops.KindHttp, header, cause, true).
+			WithDefaultWait().
+			WithReason(reason)
+	}
+	for _, statusCode := range []int{400, 403, 429, 500, 502, 503, 504} {
+		cfg.WithHeaderCheck(statusCode, withStatus(statusCode, check))
+	}
+	return cfg
+}
+
+// awsThrottlingCodes lists the error codes AWS SDKs retry as throttling
+// awsThrottlingCodes 列出 AWS SDK 作为限流重试的错误码
+var awsThrottlingCodes = map[string]bool{
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"RequestThrottledException":              true,
+	"TooManyRequestsException":               true,
+	"ProvisionedThroughputExceededException": true,
+	"TransactionInProgressException":         true,
+	"RequestLimitExceeded":                   true,
+	"BandwidthLimitExceeded":                 true,
+	"LimitExceededException":                 true,
+	"RequestThrottled":                       true,
+	"SlowDown":                               true,
+	"PriorRequestNotComplete":                true,
+	"EC2ThrottledException":                  true,
+}
+
+// awsTransientCodes lists the error codes AWS SDKs retry as transient
+// awsTransientCodes 列出 AWS SDK 作为瞬时错误重试的错误码
+var awsTransientCodes = map[string]bool{
+	"RequestTimeout":          true,
+	"RequestTimeoutException": true,
+	"InternalError":           true,
+	"InternalFailure":         true,
+	"ServiceUnavailable":      true,
+	"IDPCommunicationError":   true,
+}
+
+// awsXMLCode matches the <Code> element of S3 and EC2 style XML errors
+// awsXMLCode 匹配 S3 和 EC2 风格 XML 错误中的 <Code> 元素
+var awsXMLCode = regexp.MustCompile(`<Code>\s*([^<\s]+)\s*</Code>`)
+
+// awsErrorCode extracts the AWS error code from the header or body
+// awsErrorCode 从响应头或响应体中提取 AWS 错误码
+func awsErrorCode(header http.Header, content []byte) string {
+	if value := header.Get("X-Amzn-ErrorType"); value != "" {
+		code, _, _ := strings.Cut(value, ":")
+		return code
+	}
+	var body struct {
+		Type  string `json:"__type"`
+		Code  string `json:"code"`
+		Upper string `json:"Code"`
+	}
+	if err := json.Unmarshal(content, &body); err == nil {
+		for _, value := range []string{body.Type, body.Code, body.Upper} {
+			if value != "" {
+				// "__type" may carry a namespace like "com.amazonaws.dynamodb.v20120810#ThrottlingException"
+				// "__type" 可能带有命名空间，如 "com.amazonaws.dynamodb.v20120810#ThrottlingException"
+				if idx := strings.LastIndexByte(value, '#'); idx >= 0 {
+					value = value[idx+1:]
+				}
+				return value
+			}
+		}
+		return ""
+	}
+	if match := awsXMLCode.FindSubmatch(content); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+// GitHub creates a Config recognizing GitHub primary and secondary rate limits on 403 and 429
+// Wait time comes from Retry-After, then X-RateLimit-Reset, then one minute
+//
+// GitHub 创建识别 GitHub 在 403 和 429 上的主要和次级速率限制的 Config
+// 等待时间依次取自 Retry-After、X-RateLimit-Reset，最后为一分钟
+func GitHub() *restyoops.Config {
+	cfg := restyoops.NewConfig()
+	check := func(header http.Header, content []byte) *restyoops.Oops {
+		var reason string
+		switch {
+		case header.Get("X-RateLimit-Remaining") == "0":
+			reason = ReasonRateLimited
+		case strings.Contains(strings.ToLower(string(content)), "secondary rate limit"):
+			reason = ReasonSecondaryRateLimit
+		default:
+			return nil
+		}
+		waitTime, ok := retryAfter(header)
+		if !ok && reason == ReasonRateLimited {
+			waitTime, ok = rateLimitReset(header)
+		}
+		if !ok {
+			waitTime = time.Minute
+		}
+		cause := fmt.Errorf("github %s", strings.ReplaceAll(reason, "_", " "))
+		return newOops(restyoops.KindHttp, header, cause, true).
+			WithWaitTime(waitTime).
+			WithReason(reason)
+	}
+	for _, statusCode := range []int{403, 429} {
+		cfg.WithHeaderCheck(statusCode, withStatus(statusCode, check))
+	}
+	return cfg
+}
+
+// rateLimitReset computes the wait until the X-RateLimit-Reset epoch seconds
+// rateLimitReset 计算距离 X-RateLimit-Reset 纪元秒的等待时间
+func rateLimitReset(header http.Header) (time.Duration, bool) {
+	seconds, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(time.Unix(seconds, 0)), 0), true
+}
+
+// Stripe creates a Config following the Stripe-Should-Retry header on error responses
+// Stripe 创建在错误响应上遵循 Stripe-Should-Retry 头的 Config
+func Stripe() *restyoops.Config {
+	cfg := restyoops.NewConfig()
+	check := func(header http.Header, content []byte) *restyoops.Oops {
+		retryable, err := strconv.ParseBool(header.Get("Stripe-Should-Retry"))
+		if err != nil {
+			return nil
+		}
+		reason := ReasonShouldNotRetry
+		if retryable {
+			reason = ReasonShouldRetry
+		}
+		cause := fmt.Errorf("stripe should retry %t", retryable)
+		oops := newOops(restyoops.KindHttp, header, cause, retryable).WithReason(reason)
+		if retryable {
+			oops.WithDefaultWait()
+		}
+		return oops
+	}
+	for _, statusCode := range []int{400, 401, 402, 403, 404, 409, 424, 429, 500, 502, 503, 504} {
+		cfg.WithHeaderCheck(statusCode, withStatus(statusCode, check))
+	}
+	return cfg
+}
+
+// Google creates a Config classifying Google API errors by the "error.status" field
+// Retryable statuses honor the RetryInfo delay in "error.details"
+//
+// Google 创建按 "error.status" 字段分类 Google API 错误的 Config
+// 可重试的状态遵循 "error.details" 中 RetryInfo 的延迟
+func Google() *restyoops.Config {
+	cfg := restyoops.NewConfig()
+	for _, statusCode := range []int{400, 401, 403, 404, 409, 429, 499, 500, 501, 503, 504} {
+		cfg.WithContentCheck(statusCode, func(contentType string, content []byte) *restyoops.Oops {
+			var body googleError
+			if err := json.Unmarshal(content, &body); err != nil || body.Error.Status == "" {
+				return nil
+			}
+			retryable, ok := googleStatuses[body.Error.Status]
+			if !ok {
+				return nil
+			}
+			cause := fmt.Errorf("google %s: %s", body.Error.Status, body.Error.Message)
+			oops := restyoops.NewOops(restyoops.KindHttp, statusCode, cause, retryable).
+				WithContentType(contentType).
+				WithReason(strings.ToLower(body.Error.Status))
+			if retryable {
+				if delay, ok := body.retryDelay(); ok {
+					oops.WithWaitTime(delay)
+				} else {
+					oops.WithDefaultWait()
+				}
+			}
+			return oops
+		})
+	}
+	return cfg
+}
+
+// googleStatuses maps the canonical status names to retryable
+// googleStatuses 将规范状态名映射到是否可重试
+var googleStatuses = map[string]bool{
+	"RESOURCE_EXHAUSTED":  true,
+	"UNAVAILABLE":         true,
+	"DEADLINE_EXCEEDED":   true,
+	"ABORTED":             true,
+	"INVALID_ARGUMENT":    false,
+	"FAILED_PRECONDITION": false,
+	"OUT_OF_RANGE":        false,
+	"UNAUTHENTICATED":     false,
+	"PERMISSION_DENIED":   false,
+	"NOT_FOUND":           false,
+	"ALREADY_EXISTS":      false,
+	"UNIMPLEMENTED":       false,
+	"CANCELLED":           false,
+}
+
+// googleError is the JSON error body of Google APIs
+// googleError 是 Google API 的 JSON 错误体
+type googleError struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			Type       string `json:"@type"`
+			RetryDelay string `json:"retryDelay"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// retryDelay returns the RetryInfo delay, false when missing
+// retryDelay 返回 RetryInfo 中的延迟，缺失时返回 false
+func (e *googleError) retryDelay() (time.Duration, bool) {
+	for _, detail := range e.Error.Details {
+		if !strings.HasSuffix(detail.Type, "google.rpc.RetryInfo") {
+			continue
+		}
+		if delay, err := time.ParseDuration(detail.RetryDelay); err == nil && delay >= 0 {
+			return delay, true
+		}
+	}
+	return 0, false
+}
+
+// Cloudflare creates a Config recognizing Cloudflare 52x origin errors, challenges and 1015 rate limits
+// Cloudflare 创建识别 Cloudflare 52x 源站错误、质询页面和 1015 速率限制的 Config
+func Cloudflare() *restyoops.Config {
+	cfg := restyoops.NewConfig()
+	for statusCode, item := range cloudflareOrigins {
+		cfg.WithHeaderCheck(statusCode, withStatus(statusCode, func(header http.Header, content []byte) *restyoops.Oops {
+			cause := fmt.Errorf("cloudflare %d: %s", statusCode, strings.ReplaceAll(item.reason, "_", " "))
+			oops := newOops(restyoops.KindHttp, header, cause, item.retryable).WithReason(item.reason)
+			if item.retryable {
+				oops.WithDefaultWait()
+			}
+			return oops
+		}))
+	}
+	cfg.WithHeaderCheck(403, withStatus(403, func(header http.Header, content []byte) *restyoops.Oops {
+		if header.Get("Cf-Mitigated") != "challenge" {
+			return nil
+		}
+		return newOops(restyoops.KindBlock, header, errors.New("cloudflare challenge"), false).WithReason(ReasonChallenge)
+	}))
+	cfg.WithHeaderCheck(429, withStatus(429, func(header http.Header, content []byte) *restyoops.Oops {
+		if !strings.Contains(string(content), "error code: 1015") {
+			return nil
+		}
+		oops := newOops(restyoops.KindHttp, header, errors.New("cloudflare 1015 rate limited"), true).WithReason(ReasonRateLimited)
+		if wait, ok := retryAfter(header); ok {
+			return oops.WithWaitTime(wait)
+		}
+		return oops.WithDefaultWait()
+	}))
+	return cfg
+}
+
+// cloudflareOrigin describes a Cloudflare origin error status
+// cloudflareOrigin 描述 Cloudflare 源站错误状态码
+type cloudflareOrigin struct {
+	reason    string
+	retryable bool
+}
+
+// cloudflareOrigins maps the Cloudflare 52x status codes
+// cloudflareOrigins 映射 Cloudflare 52x 状态码
+var cloudflareOrigins = map[int]*cloudflareOrigin{
+	520: {reason: "origin_unknown_error", retryable: true},
+	521: {reason: "origin_down", retryable: true},
+	522: {reason: "origin_connection_timeout", retryable: true},
+	523: {reason: "origin_unreachable", retryable: false},
+	524: {reason: "origin_timeout", retryable: true},
+	525: {reason: "origin_ssl_handshake_failed", retryable: false},
+	526: {reason: "origin_invalid_ssl_certificate", retryable: false},
+	527: {reason: "railgun_error", retryable: true},
+	530: {reason: "origin_dns_error", retryable: false},
+}
+
+// Combine stacks the presets into one Config, checks of the same status code run in sequence and the first Oops wins
+// Unlike Config.Merge, which replaces checks of the same status code, nothing is dropped
+// Scalars and lists are taken from a preset only when they differ from the NewConfig defaults,
+// so the defaults of a later preset do not reset the settings of an earlier one
+//
+// Combine 将多个预设叠加为一个 Config，相同状态码的检查按顺序运行，第一个 Oops 生效
+// 与替换相同状态码检查的 Config.Merge 不同，不会丢弃任何检查
+// 标量和列表仅在与 NewConfig 默认值不同时才从预设中获取，
+// 因此后面预设的默认值不会重置前面预设的设置
+func Combine(presets ...*restyoops.Config) *restyoops.Config {
+	res := restyoops.NewConfig()
+	for _, preset := range presets {
+		contentChecks := res.ContentChecks
+		headerChecks := res.HeaderChecks
+		res = res.Merge(overrideOf(preset))
+		for statusCode, check := range preset.ContentChecks {
+			if previous, ok := contentChecks[statusCode]; ok {
+				res.ContentChecks[statusCode] = chainContentChecks(previous, check)
+			}
+		}
+		for statusCode, check := range preset.HeaderChecks {
+			if previous, ok := headerChecks[statusCode]; ok {
+				res.HeaderChecks[statusCode] = chainHeaderChecks(previous, check)
+			}
+		}
+	}
+	return res
+}
+
+// overrideOf returns an override holding the maps of the preset, and its scalars and lists differing from the defaults
+// overrideOf 返回包含预设映射，以及与默认值不同的标量和列表的覆盖配置
+func overrideOf(preset *restyoops.Config) *restyoops.Config {
+	defaults := restyoops.NewConfig()
+	res := restyoops.NewOverride()
+	maps.Copy(res.StatusOptions, preset.StatusOptions)
+	maps.Copy(res.KindOptions, preset.KindOptions)
+	maps.Copy(res.ContentChecks, preset.ContentChecks)
+	maps.Copy(res.HeaderChecks, preset.HeaderChecks)
+	maps.Copy(res.LogLevels, preset.LogLevels)
+	maps.Copy(res.KindSeverities, preset.KindSeverities)
+	maps.Copy(res.StatusSeverities, preset.StatusSeverities)
+	maps.Copy(res.ReasonSeverities, preset.ReasonSeverities)
+	maps.Copy(res.BusinessCodeSeverities, preset.BusinessCodeSeverities)
+	if preset.DefaultWait != defaults.DefaultWait {
+		res.WithDefaultWait(preset.DefaultWait)
+	}
+	if preset.SnippetLimit != defaults.SnippetLimit {
+		res.WithBodySnippet(preset.SnippetLimit)
+	}
+	if preset.BodyLimit != defaults.BodyLimit {
+		res.WithBodyLimit(preset.BodyLimit)
+	}
+	if preset.DefaultLogLevel != defaults.DefaultLogLevel {
+		res.WithDefaultLogLevel(preset.DefaultLogLevel)
+	}
+	if !slices.Equal(preset.RetryDirectiveHeaders, defaults.RetryDirectiveHeaders) {
+		res.WithRetryDirectiveHeaders(preset.RetryDirectiveHeaders...)
+	}
+	if !slices.Equal(preset.RequestIDHeaders, defaults.RequestIDHeaders) {
+		res.WithRequestIDHeaders(preset.RequestIDHeaders...)
+	}
+	if !slices.Equal(preset.RedactQueryKeys, defaults.RedactQueryKeys) {
+		res.WithRedactQueryKeys(preset.RedactQueryKeys...)
+	}
+	if !slices.Equal(preset.RedactFields, defaults.RedactFields) {
+		res.WithRedactFields(preset.RedactFields...)
+	}
+	return res
+}
+
+// chainContentChecks runs the checks in sequence, the first Oops wins
+// chainContentChecks 按顺序运行检查，第一个 Oops 生效
+func chainContentChecks(checks ...restyoops.ContentCheckFunc) restyoops.ContentCheckFunc {
+	return func(contentType string, content []byte) *restyoops.Oops {
+		for _, check := range checks {
+			if oops := check(contentType, content); oops != nil {
+				return oops
+			}
+		}
+		return nil
+	}
+}
+
+// chainHeaderChecks runs the checks in sequence, the first Oops wins
+// chainHeaderChecks 按顺序运行检查，第一个 Oops 生效
+func chainHeaderChecks(checks ...restyoops.HeaderCheckFunc) restyoops.HeaderCheckFunc {
+	return func(header http.Header, content []byte) *restyoops.Oops {
+		for _, check := range checks {
+			if oops := check(header, content); oops != nil {
+				return oops
+			}
+		}
+		return nil
+	}
+}
+
+// newOops creates an Oops with the content type taken from the header
+// newOops 创建 Oops，内容类型取自响应头
+func newOops(kind restyoops.Kind, header http.Header, cause error, retryable bool) *restyoops.Oops {
+	return restyoops.NewOops(kind, 0, cause, retryable).WithContentType(header.Get("Content-Type"))
+}
+
+// withStatus fills the status code of the Oops returned by the check
+// withStatus 填充检查返回的 Oops 的状态码
+func withStatus(statusCode int, check restyoops.HeaderCheckFunc) restyoops.HeaderCheckFunc {
+	return func(header http.Header, content []byte) *restyoops.Oops {
+		oops := check(header, content)
+		if oops != nil {
+			oops.StatusCode = statusCode
+		}
+		return oops
+	}
+}
+
+// retryAfter parses the Retry-After header in seconds or HTTP date
+// retryAfter 解析秒数或 HTTP 日期格式的 Retry-After 头
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
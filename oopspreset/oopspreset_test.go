@@ -0,0 +1,214 @@
+package oopspreset_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/oopspreset"
+)
+
+// detectFixture serves the recorded response in testdata and classifies it with the Config
+// detectFixture 提供 testdata 中录制的响应并使用 Config 分类
+func detectFixture(t *testing.T, cfg *restyoops.Config, name string) *restyoops.Oops {
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, file.Close()) }()
+
+	recorded, err := http.ReadResponse(bufio.NewReader(file), nil)
+	require.NoError(t, err)
+	content, err := io.ReadAll(recorded.Body)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, values := range recorded.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(recorded.StatusCode)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	resp, err := resty.New().R().Get(server.URL)
+	require.NoError(t, err)
+	return restyoops.Detect(cfg, resp, nil)
+}
+
+// TestAWS tests AWS throttling codes in JSON, XML and header forms
+// TestAWS 测试 JSON、XML 和响应头形式的 AWS 限流错误码
+func TestAWS(t *testing.T) {
+	cfg := oopspreset.AWS()
+
+	oops := detectFixture(t, cfg, "aws_dynamodb_throttling.http")
+	require.Equal(t, restyoops.KindHttp, oops.Kind)
+	require.Equal(t, http.StatusBadRequest, oops.StatusCode)
+	require.True(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonThrottling, oops.Reason)
+	require.Equal(t, cfg.DefaultWait, oops.WaitTime)
+	require.Contains(t, oops.Cause.Error(), "ProvisionedThroughputExceededException")
+
+	oops = detectFixture(t, cfg, "aws_s3_slowdown.http")
+	require.True(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonThrottling, oops.Reason)
+	require.Equal(t, "application/xml", oops.ContentType)
+
+	oops = detectFixture(t, cfg, "aws_ec2_request_limit.http")
+	require.True(t, oops.Retryable)
+	require.Contains(t, oops.Cause.Error(), "RequestLimitExceeded")
+
+	oops = detectFixture(t, cfg, "aws_apigateway_throttle.http")
+	require.Equal(t, http.StatusTooManyRequests, oops.StatusCode)
+	require.Contains(t, oops.Cause.Error(), "TooManyRequestsException")
+
+	// Other codes fall back to the status code defaults
+	// 其它错误码回退到状态码默认值
+	oops = detectFixture(t, cfg, "aws_validation.http")
+	require.False(t, oops.Retryable)
+	require.Equal(t, "bad_request", oops.Reason)
+}
+
+// TestGitHub tests primary and secondary rate limits are retryable, other 403s are not
+// TestGitHub 测试主要和次级速率限制可重试，其它 403 不可重试
+func TestGitHub(t *testing.T) {
+	cfg := oopspreset.GitHub()
+
+	oops := detectFixture(t, cfg, "github_primary_rate_limit.http")
+	require.True(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonRateLimited, oops.Reason)
+	require.Equal(t, time.Duration(0), oops.WaitTime) // recorded reset time has passed
+
+	oops = detectFixture(t, cfg, "github_secondary_rate_limit.http")
+	require.True(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonSecondaryRateLimit, oops.Reason)
+	require.Equal(t, time.Minute, oops.WaitTime)
+
+	oops = detectFixture(t, cfg, "github_forbidden.http")
+	require.False(t, oops.Retryable)
+	require.Equal(t, "forbidden", oops.Reason)
+}
+
+// TestStripe tests Stripe-Should-Retry overrides the status code defaults in both directions
+// TestStripe 测试 Stripe-Should-Retry 在两个方向上覆盖状态码默认值
+func TestStripe(t *testing.T) {
+	cfg := oopspreset.Stripe()
+
+	oops := detectFixture(t, cfg, "stripe_lock_timeout.http")
+	require.Equal(t, http.StatusConflict, oops.StatusCode)
+	require.True(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonShouldRetry, oops.Reason)
+
+	oops = detectFixture(t, cfg, "stripe_api_error.http")
+	require.Equal(t, http.StatusInternalServerError, oops.StatusCode)
+	require.False(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonShouldNotRetry, oops.Reason)
+}
+
+// TestGoogle tests Google API errors are classified by error.status with the RetryInfo delay
+// TestGoogle 测试 Google API 错误按 error.status 分类并使用 RetryInfo 延迟
+func TestGoogle(t *testing.T) {
+	cfg := oopspreset.Google()
+
+	oops := detectFixture(t, cfg, "google_resource_exhausted.http")
+	require.True(t, oops.Retryable)
+	require.Equal(t, "resource_exhausted", oops.Reason)
+	require.Equal(t, 30*time.Second, oops.WaitTime)
+
+	oops = detectFixture(t, cfg, "google_invalid_argument.http")
+	require.False(t, oops.Retryable)
+	require.Equal(t, "invalid_argument", oops.Reason)
+
+	oops = detectFixture(t, cfg, "google_permission_denied_500.http")
+	require.Equal(t, http.StatusInternalServerError, oops.StatusCode)
+	require.False(t, oops.Retryable)
+	require.Equal(t, "permission_denied", oops.Reason)
+}
+
+// TestCloudflare tests 52x origin errors, challenges and 1015 rate limits
+// TestCloudflare 测试 52x 源站错误、质询页面和 1015 速率限制
+func TestCloudflare(t *testing.T) {
+	cfg := oopspreset.Cloudflare()
+
+	oops := detectFixture(t, cfg, "cloudflare_522.http")
+	require.Equal(t, 522, oops.StatusCode)
+	require.True(t, oops.Retryable)
+	require.Equal(t, "origin_connection_timeout", oops.Reason)
+
+	oops = detectFixture(t, cfg, "cloudflare_526.http")
+	require.False(t, oops.Retryable)
+	require.Equal(t, "origin_invalid_ssl_certificate", oops.Reason)
+
+	oops = detectFixture(t, cfg, "cloudflare_challenge.http")
+	require.Equal(t, restyoops.KindBlock, oops.Kind)
+	require.False(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonChallenge, oops.Reason)
+
+	oops = detectFixture(t, cfg, "cloudflare_1015.http")
+	require.True(t, oops.Retryable)
+	require.Equal(t, oopspreset.ReasonRateLimited, oops.Reason)
+	require.Equal(t, 10*time.Second, oops.WaitTime)
+}
+
+// TestCombine tests stacked presets keep the checks sharing a status code
+// TestCombine 测试叠加的预设保留共享状态码的检查
+func TestCombine(t *testing.T) {
+	cfg := oopspreset.Combine(oopspreset.GitHub(), oopspreset.Cloudflare())
+	require.NoError(t, cfg.Validate())
+
+	oops := detectFixture(t, cfg, "cloudflare_522.http")
+	require.Equal(t, "origin_connection_timeout", oops.Reason)
+	oops = detectFixture(t, cfg, "github_secondary_rate_limit.http")
+	require.Equal(t, oopspreset.ReasonSecondaryRateLimit, oops.Reason)
+	oops = detectFixture(t, cfg, "cloudflare_challenge.http")
+	require.Equal(t, oopspreset.ReasonChallenge, oops.Reason)
+	oops = detectFixture(t, cfg, "github_forbidden.http")
+	require.Equal(t, "forbidden", oops.Reason)
+}
+
+// TestCombine_KeepsSettings tests the defaults of a later preset do not reset the settings of an earlier one
+// TestCombine_KeepsSettings 测试后面预设的默认值不会重置前面预设的设置
+func TestCombine_KeepsSettings(t *testing.T) {
+	custom := restyoops.NewConfig().
+		WithDefaultWait(5*time.Second).
+		WithBodySnippet(256).
+		WithRetryDirectiveHeaders("X-Should-Retry").
+		WithStatusRetryable(409, true, 0)
+
+	cfg := oopspreset.Combine(custom, oopspreset.Stripe(), oopspreset.GitHub())
+	require.Equal(t, 5*time.Second, cfg.DefaultWait)
+	require.Equal(t, 256, cfg.SnippetLimit)
+	require.Equal(t, []string{"X-Should-Retry"}, cfg.RetryDirectiveHeaders)
+	require.True(t, cfg.StatusOptions[409].Retryable)
+	require.Equal(t, restyoops.NewConfig().RequestIDHeaders, cfg.RequestIDHeaders)
+
+	cfg = oopspreset.Combine(oopspreset.Stripe(), oopspreset.GitHub())
+	require.Empty(t, cfg.RetryDirectiveHeaders)
+	require.Equal(t, time.Second, cfg.DefaultWait)
+
+	oops := detectFixture(t, cfg, "stripe_lock_timeout.http")
+	require.Equal(t, oopspreset.ReasonShouldRetry, oops.Reason)
+	oops = detectFixture(t, cfg, "github_secondary_rate_limit.http")
+	require.Equal(t, oopspreset.ReasonSecondaryRateLimit, oops.Reason)
+}
+
+// TestDefaultWait tests preset checks read the default wait of the Config used by Detect
+// TestDefaultWait 测试预设检查读取 Detect 所用 Config 的默认等待时间
+func TestDefaultWait(t *testing.T) {
+	oops := detectFixture(t, oopspreset.AWS().WithDefaultWait(5*time.Second), "aws_dynamodb_throttling.http")
+	require.Equal(t, 5*time.Second, oops.WaitTime)
+
+	oops = detectFixture(t, oopspreset.Stripe().WithDefaultWait(5*time.Second), "stripe_lock_timeout.http")
+	require.Equal(t, 5*time.Second, oops.WaitTime)
+
+	cfg := oopspreset.Combine(restyoops.NewConfig().WithDefaultWait(7*time.Second), oopspreset.Cloudflare())
+	oops = detectFixture(t, cfg, "cloudflare_522.http")
+	require.Equal(t, 7*time.Second, oops.WaitTime)
+	require.Equal(t, restyoops.RuleHeaderCheck, oops.Source)
+}
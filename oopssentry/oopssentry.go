@@ -0,0 +1,142 @@
+// Package oopssentry: Sentry reporter for restyoops Oops
+// Converts Oops to Sentry events with tags, a details context and a fingerprint grouping identical failures
+//
+// oopssentry: restyoops Oops 的 Sentry 上报器
+// 将 Oops 转换为 Sentry 事件，包含标签、详情上下文以及用于归并相同失败的指纹
+package oopssentry
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/yyle88/must"
+	"github.com/yyle88/restyoops"
+)
+
+// ContextKey is the key of the event context holding the Oops details
+// ContextKey 是保存 Oops 详情的事件上下文键
+const ContextKey = "restyoops"
+
+// Tag keys set on events
+// 设置在事件上的标签键
+const (
+	TagKind      = "restyoops.kind"
+	TagStatus    = "restyoops.status"
+	TagHost      = "restyoops.host"
+	TagReason    = "restyoops.reason"
+	TagSeverity  = "restyoops.severity"
+	TagRetryable = "restyoops.retryable"
+)
+
+// Reporter sends Oops to Sentry, implementing restyoops.Reporter
+// Reporter 将 Oops 发送到 Sentry，实现 restyoops.Reporter
+type Reporter struct {
+	hub *sentry.Hub
+}
+
+// NewReporter creates a Reporter sending through the hub, the hub bound to the context takes precedence
+// NewReporter 创建通过 hub 发送的 Reporter，绑定在上下文上的 hub 优先
+func NewReporter(hub *sentry.Hub) *Reporter {
+	return &Reporter{hub: must.Full(hub)}
+}
+
+// Report sends the Oops as a Sentry event
+// Report 将 Oops 作为 Sentry 事件发送
+func (r *Reporter) Report(ctx context.Context, oops *restyoops.Oops) {
+	hub := r.hub
+	if ctxHub := sentry.GetHubFromContext(ctx); ctxHub != nil {
+		hub = ctxHub
+	}
+	hub.CaptureEvent(NewEvent(oops))
+}
+
+// NewEvent converts the Oops to a Sentry event, grouped by the Oops fingerprint
+// NewEvent 将 Oops 转换为 Sentry 事件，按 Oops 指纹归组
+func NewEvent(oops *restyoops.Oops) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = Level(oops)
+	event.Message = message(oops)
+	event.Transaction = oops.Route()
+	event.Fingerprint = []string{oops.Fingerprint()}
+	event.Exception = []sentry.Exception{{
+		Type:  errorType(oops),
+		Value: message(oops),
+	}}
+
+	event.Tags[TagKind] = oops.Kind.String()
+	event.Tags[TagRetryable] = strconv.FormatBool(oops.Retryable)
+	if oops.StatusCode > 0 {
+		event.Tags[TagStatus] = strconv.Itoa(oops.StatusCode)
+	}
+	if u, err := url.Parse(oops.URL); err == nil && u.Hostname() != "" {
+		event.Tags[TagHost] = u.Hostname()
+	}
+	if oops.Reason != "" {
+		event.Tags[TagReason] = oops.Reason
+	}
+	if oops.Severity != "" {
+		event.Tags[TagSeverity] = oops.Severity.String()
+	}
+
+	details := sentry.Context{}
+	if oops.URL != "" {
+		details["url"] = oops.URL
+	}
+	if oops.RequestID != "" {
+		details["request_id"] = oops.RequestID
+	}
+	if oops.ResponseRequestID != "" {
+		details["response_request_id"] = oops.ResponseRequestID
+	}
+	if oops.BusinessCode != "" {
+		details["business_code"] = oops.BusinessCode
+	}
+	if oops.BodySnippet != "" {
+		details["body_snippet"] = oops.BodySnippet
+	}
+	if len(oops.Attempts) > 0 {
+		details["attempts"] = oops.Summary()
+	}
+	if oops.Source != "" {
+		details["source"] = string(oops.Source)
+	}
+	event.Contexts[ContextKey] = details
+	return event
+}
+
+// Level returns the Sentry level of the Oops severity, critical maps to fatal and unset severity to warning
+// Level 返回 Oops 严重程度对应的 Sentry 级别，critical 对应 fatal，未设置时为 warning
+func Level(oops *restyoops.Oops) sentry.Level {
+	switch oops.Severity {
+	case restyoops.SeverityDebug:
+		return sentry.LevelDebug
+	case restyoops.SeverityInfo:
+		return sentry.LevelInfo
+	case restyoops.SeverityError:
+		return sentry.LevelError
+	case restyoops.SeverityCritical:
+		return sentry.LevelFatal
+	default:
+		return sentry.LevelWarning
+	}
+}
+
+// message describes the Oops by its cause, falling back to the Kind
+// message 使用原因描述 Oops，没有原因时使用 Kind
+func message(oops *restyoops.Oops) string {
+	if oops.Cause != nil {
+		return oops.Cause.Error()
+	}
+	return oops.Kind.String()
+}
+
+// errorType names the exception by reason, falling back to the Kind
+// errorType 按原因命名异常，未设置时使用 Kind
+func errorType(oops *restyoops.Oops) string {
+	if oops.Reason != "" {
+		return oops.Reason
+	}
+	return oops.Kind.String()
+}
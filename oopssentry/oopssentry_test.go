@@ -0,0 +1,125 @@
+package oopssentry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/oopssentry"
+)
+
+// fakeTransport collects the events in memory, in place of sending them to Sentry
+// fakeTransport 在内存中收集事件，代替发送到 Sentry
+type fakeTransport struct {
+	mutex  sync.Mutex
+	events []*sentry.Event
+}
+
+func (f *fakeTransport) Flush(timeout time.Duration) bool          { return true }
+func (f *fakeTransport) FlushWithContext(ctx context.Context) bool { return true }
+func (f *fakeTransport) Configure(options sentry.ClientOptions)    {}
+func (f *fakeTransport) Close()                                    {}
+
+func (f *fakeTransport) SendEvent(event *sentry.Event) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.events = append(f.events, event)
+}
+
+// newHub creates a hub sending to a fake transport
+// newHub 创建发送到假传输层的 hub
+func newHub(t *testing.T) (*sentry.Hub, *fakeTransport) {
+	transport := &fakeTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:       "https://public@sentry.example.com/1",
+		Transport: transport,
+	})
+	require.NoError(t, err)
+	return sentry.NewHub(client, sentry.NewScope()), transport
+}
+
+// TestReporter tests exhausted failures reach Sentry with tags, details and fingerprint
+// TestReporter 测试重试耗尽的失败携带标签、详情和指纹发送到 Sentry
+func TestReporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-7")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("busy"))
+	}))
+	defer server.Close()
+
+	hub, transport := newHub(t)
+	cfg := restyoops.NewConfig().WithDefaultWait(time.Millisecond).WithBodySnippet(64)
+	client := &http.Client{Transport: restyoops.NewTransport(restyoops.NewDetective(cfg)).
+		WithRetries(1).
+		WithHooks(restyoops.NewReportHook(oopssentry.NewReporter(hub)))}
+
+	resp, err := client.Get(server.URL + "/orders/42")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Len(t, transport.events, 1)
+	event := transport.events[0]
+	require.Equal(t, sentry.LevelWarning, event.Level)
+	require.Equal(t, "GET /orders/:id", event.Transaction)
+	require.Len(t, event.Fingerprint, 1)
+	require.Len(t, event.Fingerprint[0], 16)
+	require.Equal(t, "HTTP", event.Tags[oopssentry.TagKind])
+	require.Equal(t, "503", event.Tags[oopssentry.TagStatus])
+	require.Equal(t, "127.0.0.1", event.Tags[oopssentry.TagHost])
+	require.Equal(t, "service_unavailable", event.Tags[oopssentry.TagReason])
+	require.Equal(t, "true", event.Tags[oopssentry.TagRetryable])
+	require.Equal(t, "service_unavailable", event.Exception[0].Type)
+
+	details := event.Contexts[oopssentry.ContextKey]
+	require.Equal(t, "busy", details["body_snippet"])
+	require.Equal(t, "2 attempts: 503, 503", details["attempts"])
+	require.Equal(t, "req-7", details["response_request_id"])
+}
+
+// TestReporter_Attach tests a resty client reports once its retries are exhausted, not the attempts before
+// TestReporter_Attach 测试 resty 客户端在重试耗尽后上报一次，而不是之前的每次尝试
+func TestReporter_Attach(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	hub, transport := newHub(t)
+	client := resty.New().
+		SetRetryCount(2).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			return resp.StatusCode() == http.StatusServiceUnavailable
+		})
+	restyoops.NewDetective(restyoops.NewConfig()).Attach(client, restyoops.NewReportHook(oopssentry.NewReporter(hub)))
+
+	_, err := client.R().Get(server.URL)
+	require.NoError(t, err)
+	require.Len(t, transport.events, 1)
+	require.Equal(t, "503", transport.events[0].Tags[oopssentry.TagStatus])
+}
+
+// TestNewEvent tests the event level follows the severity and the same failure shares the fingerprint
+// TestNewEvent 测试事件级别跟随严重程度，相同失败共享指纹
+func TestNewEvent(t *testing.T) {
+	first := restyoops.NewOops(restyoops.KindBlock, http.StatusForbidden, http.ErrNotSupported, false).
+		WithSeverity(restyoops.SeverityCritical)
+	first.URL = "https://api.example.com/users/1001"
+	second := restyoops.NewOops(restyoops.KindBlock, http.StatusForbidden, http.ErrNotSupported, false)
+	second.URL = "https://api.example.com/users/2002"
+
+	event := oopssentry.NewEvent(first)
+	require.Equal(t, sentry.LevelFatal, event.Level)
+	require.Equal(t, "BLOCK", event.Exception[0].Type)
+	require.Equal(t, http.ErrNotSupported.Error(), event.Message)
+	require.Equal(t, event.Fingerprint, oopssentry.NewEvent(second).Fingerprint)
+	require.Equal(t, sentry.LevelWarning, oopssentry.NewEvent(second).Level)
+}
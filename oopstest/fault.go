@@ -0,0 +1,129 @@
+package oopstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Fault is one scripted outcome of a request, either a response or a transport failure
+// Fault 是请求的一个脚本化结果，可以是响应，也可以是传输失败
+type Fault struct {
+	Name    string                                          // Fault name used in messages // 消息中使用的故障名称
+	respond func(req *http.Request) (*http.Response, error) // Produces the outcome // 产生结果
+}
+
+// NewFault creates a Fault from a custom respond function
+// NewFault 使用自定义的响应函数创建 Fault
+func NewFault(name string, respond func(req *http.Request) (*http.Response, error)) *Fault {
+	return &Fault{Name: name, respond: respond}
+}
+
+// OK responds 200 with the body
+// OK 以 200 和响应体响应
+func OK(body string) *Fault {
+	return Status(http.StatusOK, body)
+}
+
+// Status responds the status code with the body
+// Status 以状态码和响应体响应
+func Status(statusCode int, body string) *Fault {
+	return NewFault(strconv.Itoa(statusCode), func(req *http.Request) (*http.Response, error) {
+		return newResponse(req, statusCode, "text/plain; charset=utf-8", body), nil
+	})
+}
+
+// TooManyRequests responds 429 with the Retry-After header in seconds
+// TooManyRequests 以 429 和秒数形式的 Retry-After 头响应
+func TooManyRequests(retryAfter time.Duration) *Fault {
+	return NewFault("429", func(req *http.Request) (*http.Response, error) {
+		resp := newResponse(req, http.StatusTooManyRequests, "text/plain; charset=utf-8", "too many requests")
+		resp.Header.Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+		return resp, nil
+	})
+}
+
+// Captcha responds 200 with an HTML captcha page
+// Captcha 以 200 和 HTML 验证码页面响应
+func Captcha() *Fault {
+	return NewFault("captcha", func(req *http.Request) (*http.Response, error) {
+		body := `<html><head><title>Security Check</title></head><body><div class="captcha">Please complete the captcha to continue</div></body></html>`
+		return newResponse(req, http.StatusOK, "text/html; charset=utf-8", body), nil
+	})
+}
+
+// BusinessCode responds 200 with a JSON body like {"code":1001,"message":"..."}
+// BusinessCode 以 200 和类似 {"code":1001,"message":"..."} 的 JSON 响应体响应
+func BusinessCode(code int, message string) *Fault {
+	return NewFault("business_"+strconv.Itoa(code), func(req *http.Request) (*http.Response, error) {
+		body, err := json.Marshal(map[string]any{"code": code, "message": message})
+		if err != nil {
+			return nil, err
+		}
+		return newResponse(req, http.StatusOK, "application/json", string(body)), nil
+	})
+}
+
+// DNSError fails with a DNS lookup error, notFound marks the host as nonexistent
+// DNSError 以 DNS 查询错误失败，notFound 表示主机不存在
+func DNSError(notFound bool) *Fault {
+	return NewFault("dns", func(req *http.Request) (*http.Response, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: req.URL.Hostname(), IsNotFound: notFound}
+	})
+}
+
+// Reset fails with a connection reset by peer
+// Reset 以连接被对端重置失败
+func Reset() *Fault {
+	return NewFault("reset", func(req *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	})
+}
+
+// Timeout fails with an I/O timeout
+// Timeout 以 I/O 超时失败
+func Timeout() *Fault {
+	return NewFault("timeout", func(req *http.Request) (*http.Response, error) {
+		return nil, os.ErrDeadlineExceeded
+	})
+}
+
+// Delay waits before producing the fault, returning early with the context error when the request is canceled
+// Delay 在产生故障前等待，请求被取消时提前返回上下文错误
+func Delay(delay time.Duration, fault *Fault) *Fault {
+	return NewFault("delay_"+fault.Name, func(req *http.Request) (*http.Response, error) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return nil, context.Cause(req.Context())
+		case <-timer.C:
+		}
+		return fault.respond(req)
+	})
+}
+
+// newResponse creates a response of the request
+// newResponse 创建请求的响应
+func newResponse(req *http.Request, statusCode int, contentType string, body string) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	return &http.Response{
+		Status:        strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
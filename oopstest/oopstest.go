@@ -0,0 +1,185 @@
+// Package oopstest: fault injection and assertions to test the handling of each Kind
+// Scripts emit faults in sequence or by probability, via an http.RoundTripper or an httptest server
+//
+// oopstest: 用于测试各 Kind 处理逻辑的故障注入和断言
+// 脚本按顺序或按概率产生故障，可通过 http.RoundTripper 或 httptest 服务端使用
+package oopstest
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yyle88/must"
+	"github.com/yyle88/restyoops"
+)
+
+// Script picks the Fault of each request and counts the calls, safe in concurrent use
+// Script 为每个请求选择 Fault 并统计调用次数，可安全并发使用
+type Script struct {
+	mutex   sync.Mutex
+	faults  []*Fault
+	weights []float64  // probability of each fault, nil in sequence mode // 每个故障的概率，顺序模式下为 nil
+	rnd     *rand.Rand // random source in probability mode // 概率模式下的随机源
+	calls   int
+}
+
+// NewSequence creates a Script emitting the faults in sequence, the last one repeats once all are used
+// NewSequence 创建按顺序产生故障的 Script，全部用完后重复最后一个
+func NewSequence(faults ...*Fault) *Script {
+	must.Have(faults)
+	return &Script{faults: faults}
+}
+
+// NewRandom creates a Script emitting faults by probability, seeded so runs are repeatable
+// Requests not picking any fault get the fallback
+//
+// NewRandom 创建按概率产生故障的 Script，使用种子使运行结果可复现
+// 未选中任何故障的请求使用 fallback
+func NewRandom(seed int64, fallback *Fault) *Script {
+	must.Full(fallback)
+	return &Script{
+		faults:  []*Fault{fallback},
+		weights: []float64{0},
+		rnd:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// WithFault adds a fault picked with the probability, in probability mode only
+// WithFault 添加按概率选中的故障，仅用于概率模式
+func (s *Script) WithFault(probability float64, fault *Fault) *Script {
+	must.Full(s.rnd)
+	must.Full(fault)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, fault)
+	s.weights = append(s.weights, probability)
+	return s
+}
+
+// Calls returns the count of requests served
+// Calls 返回已处理的请求数
+func (s *Script) Calls() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls
+}
+
+// next picks the fault of the next request
+// next 选择下一个请求的故障
+func (s *Script) next() *Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls++
+	if s.rnd == nil {
+		return s.faults[min(s.calls, len(s.faults))-1]
+	}
+	value := s.rnd.Float64()
+	for idx := 1; idx < len(s.faults); idx++ {
+		if value < s.weights[idx] {
+			return s.faults[idx]
+		}
+		value -= s.weights[idx]
+	}
+	return s.faults[0]
+}
+
+// RoundTrip produces the fault of the request, implementing http.RoundTripper
+// RoundTrip 产生请求对应的故障，实现 http.RoundTripper
+func (s *Script) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+	return s.next().respond(req)
+}
+
+// Client creates an http.Client sending requests through the script
+// Client 创建通过脚本发送请求的 http.Client
+func (s *Script) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+// NewServer starts an httptest server serving the script, closed when the test ends
+// Timeouts block until the client gives up, other transport failures close the connection
+//
+// NewServer 启动提供脚本的 httptest 服务端，测试结束时关闭
+// 超时会阻塞直到客户端放弃，其它传输失败会关闭连接
+func (s *Script) NewServer(t testing.TB) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := s.RoundTrip(r)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Minute):
+				}
+				return
+			}
+			if hijacker, ok := w.(http.Hijacker); ok {
+				if conn, _, hijackErr := hijacker.Hijack(); hijackErr == nil {
+					_ = conn.Close()
+				}
+			}
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Expect describes the expected Oops, zero StatusCode, Reason and WaitTime are not checked
+// Expect 描述预期的 Oops，StatusCode、Reason 和 WaitTime 为零值时不检查
+type Expect struct {
+	Kind       restyoops.Kind // Expected Kind // 预期的 Kind
+	Retryable  bool           // Expected retryable // 预期是否可重试
+	StatusCode int            // Expected status code // 预期的状态码
+	Reason     string         // Expected reason // 预期的原因
+	WaitTime   time.Duration  // Expected wait time // 预期的等待时间
+}
+
+// RequireOops fails the test when the Oops does not match the expectation
+// RequireOops 在 Oops 与预期不符时使测试失败
+func RequireOops(t testing.TB, oops *restyoops.Oops, expect Expect) {
+	t.Helper()
+	if oops == nil {
+		t.Fatalf("expected %s oops, got success", expect.Kind)
+		return
+	}
+	if oops.Kind != expect.Kind {
+		t.Fatalf("expected kind %s, got %s (%v)", expect.Kind, oops.Kind, oops.Cause)
+	}
+	if oops.Retryable != expect.Retryable {
+		t.Fatalf("expected retryable=%t, got %t (%v)", expect.Retryable, oops.Retryable, oops.Cause)
+	}
+	if expect.StatusCode != 0 && oops.StatusCode != expect.StatusCode {
+		t.Fatalf("expected status %d, got %d", expect.StatusCode, oops.StatusCode)
+	}
+	if expect.Reason != "" && oops.Reason != expect.Reason {
+		t.Fatalf("expected reason %q, got %q", expect.Reason, oops.Reason)
+	}
+	if expect.WaitTime != 0 && oops.WaitTime != expect.WaitTime {
+		t.Fatalf("expected wait %v, got %v", expect.WaitTime, oops.WaitTime)
+	}
+}
+
+// RequireSuccess fails the test when the Oops is not nil
+// RequireSuccess 在 Oops 不为 nil 时使测试失败
+func RequireSuccess(t testing.TB, oops *restyoops.Oops) {
+	t.Helper()
+	if oops != nil {
+		t.Fatalf("expected success, got %s oops (%v)", oops.Kind, oops.Cause)
+	}
+}
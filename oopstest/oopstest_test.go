@@ -0,0 +1,124 @@
+package oopstest_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/oopstest"
+)
+
+// TestFaults tests each fault is classified as the expected Kind through a resty client
+// TestFaults 测试每种故障通过 resty 客户端被分类为预期的 Kind
+func TestFaults(t *testing.T) {
+	cfg := restyoops.NewConfig().
+		WithContentCheck(200, func(contentType string, content []byte) *restyoops.Oops {
+			switch {
+			case bytes.Contains(content, []byte("captcha")):
+				return restyoops.NewOops(restyoops.KindBlock, 200, errors.New("captcha"), true)
+			case bytes.Contains(content, []byte(`"code":1001`)):
+				return restyoops.NewOops(restyoops.KindBusiness, 200, errors.New("code 1001"), false)
+			}
+			return nil
+		})
+
+	cases := []struct {
+		fault  *oopstest.Fault
+		expect oopstest.Expect
+	}{
+		{oopstest.DNSError(true), oopstest.Expect{Kind: restyoops.KindNetwork, Retryable: false, Reason: restyoops.ReasonDNSNotFound}},
+		{oopstest.DNSError(false), oopstest.Expect{Kind: restyoops.KindNetwork, Retryable: true, Reason: restyoops.ReasonDNS}},
+		{oopstest.Reset(), oopstest.Expect{Kind: restyoops.KindNetwork, Retryable: true, Reason: restyoops.ReasonConnection}},
+		{oopstest.Timeout(), oopstest.Expect{Kind: restyoops.KindNetwork, Retryable: true, Reason: restyoops.ReasonTimeout}},
+		{oopstest.TooManyRequests(3 * time.Second), oopstest.Expect{Kind: restyoops.KindHttp, Retryable: true, StatusCode: 429}},
+		{oopstest.Status(http.StatusBadGateway, "bad gateway"), oopstest.Expect{Kind: restyoops.KindHttp, Retryable: true, StatusCode: 502}},
+		{oopstest.Captcha(), oopstest.Expect{Kind: restyoops.KindBlock, Retryable: true}},
+		{oopstest.BusinessCode(1001, "insufficient balance"), oopstest.Expect{Kind: restyoops.KindBusiness, Retryable: false}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.fault.Name, func(t *testing.T) {
+			script := oopstest.NewSequence(tc.fault)
+			client := resty.NewWithClient(script.Client())
+			resp, err := client.R().Get("http://api.example.com/v1/items")
+			oopstest.RequireOops(t, restyoops.Detect(cfg, resp, err), tc.expect)
+		})
+	}
+
+	resp, err := resty.NewWithClient(oopstest.NewSequence(oopstest.OK("fine")).Client()).R().Get("http://api.example.com")
+	oopstest.RequireSuccess(t, restyoops.Detect(cfg, resp, err))
+}
+
+// TestSequence tests faults run in sequence and the Transport retries past them
+// TestSequence 测试故障按顺序产生，Transport 重试直到越过这些故障
+func TestSequence(t *testing.T) {
+	script := oopstest.NewSequence(oopstest.Reset(), oopstest.Status(http.StatusServiceUnavailable, ""), oopstest.OK("done"))
+	detective := restyoops.NewDetective(restyoops.NewConfig().WithDefaultWait(time.Millisecond))
+	client := &http.Client{Transport: restyoops.NewTransport(detective).WithBase(script).WithRetries(3)}
+
+	resp, err := client.Get("http://api.example.com")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 3, script.Calls())
+
+	// The last fault repeats once all are used
+	resp, err = client.Get("http://api.example.com")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, 4, script.Calls())
+}
+
+// TestRandom tests faults are picked by probability, repeatable with the same seed
+// TestRandom 测试按概率选择故障，相同种子可复现
+func TestRandom(t *testing.T) {
+	run := func() []int {
+		script := oopstest.NewRandom(42, oopstest.OK("ok")).
+			WithFault(0.3, oopstest.Status(http.StatusServiceUnavailable, "")).
+			WithFault(0.2, oopstest.Status(http.StatusTooManyRequests, ""))
+		client := script.Client()
+		var statusCodes []int
+		for range 1000 {
+			resp, err := client.Get("http://api.example.com")
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			statusCodes = append(statusCodes, resp.StatusCode)
+		}
+		return statusCodes
+	}
+
+	statusCodes := run()
+	require.Equal(t, statusCodes, run())
+
+	counts := map[int]int{}
+	for _, statusCode := range statusCodes {
+		counts[statusCode]++
+	}
+	require.InDelta(t, 500, counts[http.StatusOK], 60)
+	require.InDelta(t, 300, counts[http.StatusServiceUnavailable], 60)
+	require.InDelta(t, 200, counts[http.StatusTooManyRequests], 60)
+}
+
+// TestNewServer tests the script served over a real connection
+// TestNewServer 测试通过真实连接提供脚本
+func TestNewServer(t *testing.T) {
+	script := oopstest.NewSequence(oopstest.TooManyRequests(2*time.Second), oopstest.Reset(), oopstest.Timeout())
+	server := script.NewServer(t)
+	client := resty.New().SetTimeout(100 * time.Millisecond)
+	cfg := restyoops.NewConfig()
+
+	resp, err := client.R().Get(server.URL)
+	require.NoError(t, err)
+	require.Equal(t, "2", resp.Header().Get("Retry-After"))
+	oopstest.RequireOops(t, restyoops.Detect(cfg, resp, err), oopstest.Expect{Kind: restyoops.KindHttp, Retryable: true, StatusCode: 429})
+
+	resp, err = client.R().Get(server.URL)
+	oopstest.RequireOops(t, restyoops.Detect(cfg, resp, err), oopstest.Expect{Kind: restyoops.KindNetwork, Retryable: true})
+
+	resp, err = client.R().Get(server.URL)
+	oopstest.RequireOops(t, restyoops.Detect(cfg, resp, err), oopstest.Expect{Kind: restyoops.KindNetwork, Retryable: true, Reason: restyoops.ReasonTimeout})
+}
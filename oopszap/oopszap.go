@@ -0,0 +1,200 @@
+// Package oopszap: Zap field encoder for restyoops Oops
+// Encodes Oops as a structured object, including attempt history and nested cause chains
+//
+// oopszap: restyoops Oops 的 zap 字段编码器
+// 将 Oops 编码为结构化对象，包含尝试历史和嵌套的原因链
+package oopszap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yyle88/restyoops"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCauseDepth limits the depth of the encoded cause chain
+// maxCauseDepth 限制编码的原因链深度
+const maxCauseDepth = 8
+
+// Oops creates a zap.Field with key "oops"
+// Oops 创建键为 "oops" 的 zap.Field
+func Oops(oops *restyoops.Oops) zap.Field {
+	return Field("oops", oops)
+}
+
+// Field creates a zap.Field with the specified key, nil Oops encodes as zap.Skip
+// Field 使用指定的键创建 zap.Field，nil Oops 编码为 zap.Skip
+func Field(key string, oops *restyoops.Oops) zap.Field {
+	if oops == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, Marshaler(oops))
+}
+
+// Marshaler returns a zapcore.ObjectMarshaler encoding the Oops
+// Marshaler 返回编码 Oops 的 zapcore.ObjectMarshaler
+func Marshaler(oops *restyoops.Oops) zapcore.ObjectMarshaler {
+	return &oopsMarshaler{oops: oops}
+}
+
+// Level returns the zap level of the Oops severity, unset severity maps to WarnLevel
+// Critical maps to ErrorLevel too, since DPanicLevel and above panic or exit in some loggers
+//
+// Level 返回 Oops 严重程度对应的 zap 级别，未设置时为 WarnLevel
+// critical 同样对应 ErrorLevel，因为 DPanicLevel 及以上级别在某些 logger 中会 panic 或退出
+func Level(oops *restyoops.Oops) zapcore.Level {
+	switch oops.Severity {
+	case restyoops.SeverityDebug:
+		return zapcore.DebugLevel
+	case restyoops.SeverityInfo:
+		return zapcore.InfoLevel
+	case restyoops.SeverityError, restyoops.SeverityCritical:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.WarnLevel
+	}
+}
+
+// oopsMarshaler encodes Oops fields
+// oopsMarshaler 编码 Oops 字段
+type oopsMarshaler struct {
+	oops *restyoops.Oops
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler
+// MarshalLogObject 实现 zapcore.ObjectMarshaler
+func (m *oopsMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	o := m.oops
+	enc.AddString("kind", o.Kind.String())
+	enc.AddInt("status", o.StatusCode)
+	enc.AddBool("retryable", o.Retryable)
+	enc.AddDuration("wait", o.WaitTime)
+	if o.Reason != "" {
+		enc.AddString("reason", o.Reason)
+	}
+	if o.Source != "" {
+		enc.AddString("source", string(o.Source))
+	}
+	if o.Severity != "" {
+		enc.AddString("severity", o.Severity.String())
+	}
+	if o.BusinessCode != "" {
+		enc.AddString("business_code", o.BusinessCode)
+	}
+	if o.ContentType != "" {
+		enc.AddString("content_type", o.ContentType)
+	}
+	if o.Method != "" {
+		enc.AddString("method", o.Method)
+	}
+	if o.URL != "" {
+		enc.AddString("url", o.URL)
+	}
+	if o.RequestID != "" {
+		enc.AddString("request_id", o.RequestID)
+	}
+	if o.ResponseRequestID != "" {
+		enc.AddString("response_request_id", o.ResponseRequestID)
+	}
+	enc.AddString("fingerprint", o.Fingerprint())
+	if o.Duration > 0 {
+		enc.AddDuration("duration", o.Duration)
+	}
+	if o.RemoteAddr != "" {
+		enc.AddString("remote_addr", o.RemoteAddr)
+	}
+	if o.BodySnippet != "" {
+		enc.AddString("body", o.BodySnippet)
+	}
+	if o.Cause != nil {
+		if err := enc.AddObject("cause", &causeMarshaler{cause: o.Cause, depth: 0}); err != nil {
+			return err
+		}
+	}
+	if len(o.Attempts) > 0 {
+		enc.AddString("summary", o.Summary())
+		if err := enc.AddArray("attempts", attemptsMarshaler(o.Attempts)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attemptsMarshaler encodes the attempt history
+// attemptsMarshaler 编码尝试历史
+type attemptsMarshaler []*restyoops.Attempt
+
+// MarshalLogArray implements zapcore.ArrayMarshaler
+// MarshalLogArray 实现 zapcore.ArrayMarshaler
+func (attempts attemptsMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, attempt := range attempts {
+		if err := enc.AppendObject(&attemptMarshaler{attempt: attempt}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attemptMarshaler encodes one attempt
+// attemptMarshaler 编码单次尝试
+type attemptMarshaler struct {
+	attempt *restyoops.Attempt
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler
+// MarshalLogObject 实现 zapcore.ObjectMarshaler
+func (m *attemptMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	a := m.attempt
+	enc.AddString("kind", a.Kind.String())
+	enc.AddInt("status", a.StatusCode)
+	enc.AddDuration("wait", a.WaitTime)
+	enc.AddDuration("duration", a.Duration)
+	enc.AddTime("timestamp", a.Timestamp)
+	if a.Cause != nil {
+		enc.AddString("cause", a.Cause.Error())
+	}
+	return nil
+}
+
+// causeMarshaler encodes an error and the errors it wraps
+// causeMarshaler 编码错误及其包装的错误
+type causeMarshaler struct {
+	cause error
+	depth int
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler
+// MarshalLogObject 实现 zapcore.ObjectMarshaler
+func (m *causeMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("msg", m.cause.Error())
+	enc.AddString("type", fmt.Sprintf("%T", m.cause))
+	if m.depth >= maxCauseDepth {
+		return nil
+	}
+
+	var wrapped []error
+	switch x := m.cause.(type) {
+	case interface{ Unwrap() []error }:
+		wrapped = x.Unwrap()
+	default:
+		if inner := errors.Unwrap(m.cause); inner != nil {
+			wrapped = []error{inner}
+		}
+	}
+	if len(wrapped) == 0 {
+		return nil
+	}
+	return enc.AddArray("wrapped", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, inner := range wrapped {
+			if inner == nil {
+				continue
+			}
+			if err := arr.AppendObject(&causeMarshaler{cause: inner, depth: m.depth + 1}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
@@ -0,0 +1,94 @@
+package oopszap_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/oopszap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestOops tests the field encodes classification and attempt history
+// TestOops 测试字段编码分类信息和尝试历史
+func TestOops(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	first := restyoops.Detect(restyoops.NewConfig(), nil, context.DeadlineExceeded)
+	oops := restyoops.NewOops(restyoops.KindHttp, http.StatusServiceUnavailable, errors.New("503"), true).
+		WithWaitTime(2 * time.Second).
+		WithReason("service_unavailable")
+	oops.WithAttempts(restyoops.NewAttempt(first, time.Second), restyoops.NewAttempt(oops, 0))
+
+	logger.Warn("request failed", oopszap.Oops(oops))
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	object := fields["oops"].(map[string]any)
+	require.Equal(t, "HTTP", object["kind"])
+	require.Equal(t, 503, object["status"])
+	require.Equal(t, true, object["retryable"])
+	require.Equal(t, 2*time.Second, object["wait"])
+	require.Equal(t, "service_unavailable", object["reason"])
+	require.Equal(t, "2 attempts: timeout, 503", object["summary"])
+
+	attempts := object["attempts"].([]any)
+	require.Len(t, attempts, 2)
+	require.Equal(t, "NETWORK", attempts[0].(map[string]any)["kind"])
+	require.Equal(t, time.Second, attempts[0].(map[string]any)["duration"])
+}
+
+// TestField_CauseChain tests nested causes are encoded, including joined errors
+// TestField_CauseChain 测试嵌套原因的编码，包括合并的错误
+func TestField_CauseChain(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	root := errors.New("connection reset")
+	cause := fmt.Errorf("read body: %w", errors.Join(root, context.Canceled))
+	oops := restyoops.NewOops(restyoops.KindNetwork, 0, cause, true)
+
+	logger.Error("request failed", oopszap.Field("issue", oops), oopszap.Field("none", nil))
+
+	fields := logs.All()[0].ContextMap()
+	require.NotContains(t, fields, "none")
+	causeObject := fields["issue"].(map[string]any)["cause"].(map[string]any)
+	require.Equal(t, "*fmt.wrapError", causeObject["type"])
+
+	joined := causeObject["wrapped"].([]any)[0].(map[string]any)
+	leaves := joined["wrapped"].([]any)
+	require.Len(t, leaves, 2)
+	require.Equal(t, "connection reset", leaves[0].(map[string]any)["msg"])
+	require.Equal(t, "context canceled", leaves[1].(map[string]any)["msg"])
+}
+
+// TestLevel tests the zap level follows the Oops severity
+// TestLevel 测试 zap 级别跟随 Oops 的严重程度
+func TestLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	oops := restyoops.NewOops(restyoops.KindBlock, http.StatusForbidden, errors.New("waf"), false).WithSeverity(restyoops.SeverityCritical)
+	require.Equal(t, zapcore.ErrorLevel, oopszap.Level(oops))
+
+	// Logging at the level never panics, even with a development logger
+	// 即使使用开发模式 logger，按该级别记录日志也不会 panic
+	development := zap.New(zapcore.NewNopCore(), zap.Development())
+	require.NotPanics(t, func() { development.Log(oopszap.Level(oops), "blocked", oopszap.Oops(oops)) })
+	require.Panics(t, func() { development.Log(zapcore.DPanicLevel, "blocked") })
+	require.Equal(t, zapcore.WarnLevel, oopszap.Level(restyoops.NewOops(restyoops.KindHttp, http.StatusBadGateway, errors.New("bad gateway"), true)))
+
+	oops = restyoops.Detect(restyoops.NewConfig(), nil, context.DeadlineExceeded)
+	logger.Log(oopszap.Level(oops), "request failed", oopszap.Oops(oops))
+	entry := logs.All()[0]
+	require.Equal(t, zapcore.WarnLevel, entry.Level)
+	require.Equal(t, "warn", entry.ContextMap()["oops"].(map[string]any)["severity"])
+}
@@ -0,0 +1,14 @@
+package restyoops
+
+// Reasons set by built-in detection, HTTP reasons derive from status text, like "service_unavailable"
+// 内置检测设置的原因，HTTP 原因由状态文本派生，如 "service_unavailable"
+const (
+	ReasonTimeout     = "timeout"       // deadline exceeded or timed out // 截止时间超时或超时
+	ReasonCanceled    = "canceled"      // context canceled // 上下文被取消
+	ReasonDNS         = "dns"           // DNS lookup issue // DNS 查询问题
+	ReasonDNSNotFound = "dns_not_found" // no such host // 无此主机
+	ReasonConnection  = "connection"    // dial, reset or refused // 拨号、重置或拒绝连接
+	ReasonTransport   = "transport"     // other transport issue // 其他传输问题
+	ReasonNetwork     = "network"       // other network issue // 其他网络问题
+	ReasonUnknown     = "unknown"       // unclassified issue // 未分类问题
+)
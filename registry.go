@@ -0,0 +1,89 @@
+package restyoops
+
+import (
+	"sync"
+
+	"github.com/yyle88/must"
+)
+
+// KindInfo describes a registered Kind, built-in or added by the application
+// KindInfo 描述已注册的 Kind，可以是内置的，也可以是应用添加的
+type KindInfo struct {
+	Kind        Kind     // Kind name, uppercase by convention // Kind 名称，按惯例使用大写
+	Retryable   bool     // Default retryable used by NewKindOops // NewKindOops 使用的默认可重试值
+	Severity    Severity // Default severity of the Kind // 该 Kind 的默认严重程度
+	Description string   // Human-readable description // 人类可读的描述
+}
+
+// kindRegistry holds the registered Kinds in registration sequence
+// kindRegistry 按注册顺序保存已注册的 Kind
+var kindRegistry = struct {
+	mutex sync.RWMutex
+	infos map[Kind]*KindInfo
+	kinds []Kind
+}{
+	infos: map[Kind]*KindInfo{},
+}
+
+func init() {
+	RegisterKind(&KindInfo{Kind: KindUnknown, Retryable: false, Severity: SeverityError, Description: "unclassified issue"})
+	RegisterKind(&KindInfo{Kind: KindNetwork, Retryable: true, Severity: SeverityWarn, Description: "network issue like timeout, DNS, TCP or TLS"})
+	RegisterKind(&KindInfo{Kind: KindHttp, Retryable: false, Severity: SeverityWarn, Description: "HTTP status code issue"})
+	RegisterKind(&KindInfo{Kind: KindParse, Retryable: false, Severity: SeverityError, Description: "response parsing issue"})
+	RegisterKind(&KindInfo{Kind: KindBlock, Retryable: false, Severity: SeverityError, Description: "request blocked by captcha, WAF or login redirect"})
+	RegisterKind(&KindInfo{Kind: KindBusiness, Retryable: false, Severity: SeverityInfo, Description: "business logic issue"})
+}
+
+// RegisterKind registers a domain-specific Kind, making it accepted by NewOops and Config
+// Call it from init, it panics when the Kind is empty or already registered
+//
+// RegisterKind 注册领域特定的 Kind，使其被 NewOops 和 Config 接受
+// 应在 init 中调用，Kind 为空或已注册时会 panic
+func RegisterKind(info *KindInfo) {
+	must.Full(info)
+	must.Nice(info.Kind)
+
+	kindRegistry.mutex.Lock()
+	defer kindRegistry.mutex.Unlock()
+	_, exists := kindRegistry.infos[info.Kind]
+	must.False(exists)
+	clone := *info
+	kindRegistry.infos[info.Kind] = &clone
+	kindRegistry.kinds = append(kindRegistry.kinds, info.Kind)
+}
+
+// LookupKind returns a copy of the registered KindInfo, false when the Kind is not registered
+// LookupKind 返回已注册 KindInfo 的副本，Kind 未注册时返回 false
+func LookupKind(kind Kind) (*KindInfo, bool) {
+	kindRegistry.mutex.RLock()
+	defer kindRegistry.mutex.RUnlock()
+	info, ok := kindRegistry.infos[kind]
+	if !ok {
+		return nil, false
+	}
+	clone := *info
+	return &clone, true
+}
+
+// RegisteredKinds returns the registered Kinds, built-in ones first
+// RegisteredKinds 返回已注册的 Kind，内置的在前
+func RegisteredKinds() []Kind {
+	kindRegistry.mutex.RLock()
+	defer kindRegistry.mutex.RUnlock()
+	return append([]Kind(nil), kindRegistry.kinds...)
+}
+
+// IsRegistered checks if the Kind is built-in or registered with RegisterKind
+// IsRegistered 检查 Kind 是否为内置的或已通过 RegisterKind 注册
+func (k Kind) IsRegistered() bool {
+	_, ok := LookupKind(k)
+	return ok
+}
+
+// NewKindOops creates an Oops with the default retryable of the registered Kind
+// NewKindOops 使用已注册 Kind 的默认可重试值创建 Oops
+func NewKindOops(kind Kind, statusCode int, cause error) *Oops {
+	info, ok := LookupKind(kind)
+	must.True(ok)
+	return NewOops(kind, statusCode, cause, info.Retryable)
+}
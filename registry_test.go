@@ -0,0 +1,72 @@
+package restyoops_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// KindQuota is a domain-specific Kind registered by the tests
+// KindQuota 是测试注册的领域特定 Kind
+const KindQuota restyoops.Kind = "QUOTA"
+
+func init() {
+	restyoops.RegisterKind(&restyoops.KindInfo{Kind: KindQuota, Retryable: true, Severity: restyoops.SeverityWarn, Description: "quota exhausted"})
+}
+
+// TestRegisterKind tests a registered Kind is accepted by NewOops and Config
+// TestRegisterKind 测试已注册的 Kind 被 NewOops 和 Config 接受
+func TestRegisterKind(t *testing.T) {
+	require.True(t, KindQuota.IsRegistered())
+	require.False(t, restyoops.Kind("MAINTENANCE").IsRegistered())
+	require.Equal(t, restyoops.KindUnknown, restyoops.RegisteredKinds()[0])
+	require.Contains(t, restyoops.RegisteredKinds(), KindQuota)
+
+	info, ok := restyoops.LookupKind(KindQuota)
+	require.True(t, ok)
+	require.Equal(t, "quota exhausted", info.Description)
+	info.Description = "changed"
+	info, _ = restyoops.LookupKind(KindQuota)
+	require.Equal(t, "quota exhausted", info.Description)
+
+	oops := restyoops.NewKindOops(KindQuota, 200, errors.New("quota"))
+	require.Equal(t, KindQuota, oops.Kind)
+	require.True(t, oops.Retryable)
+
+	cfg := restyoops.NewConfig().WithKindRetryable(KindQuota, false, 0)
+	require.NoError(t, cfg.Validate())
+
+	require.Panics(t, func() {
+		restyoops.NewOops("MAINTENANCE", 503, errors.New("maintenance"), true)
+	})
+	require.Panics(t, func() {
+		restyoops.RegisterKind(&restyoops.KindInfo{Kind: restyoops.KindHttp})
+	})
+}
+
+// TestRegisterKindLoadConfig tests config files accept registered Kinds in content checks
+// TestRegisterKindLoadConfig 测试配置文件的内容检查接受已注册的 Kind
+func TestRegisterKindLoadConfig(t *testing.T) {
+	cfg, err := restyoops.LoadConfig(strings.NewReader(`
+kinds:
+  - kind: QUOTA
+    retryable: true
+    wait: 1m
+content_checks:
+  - status: 200
+    json_path: code
+    success: [0]
+    kind: QUOTA
+    retryable: true
+`))
+	require.NoError(t, err)
+
+	oops := detectServed(t, cfg, 200, `{"code":42}`)
+	require.Equal(t, KindQuota, oops.Kind)
+
+	_, err = restyoops.LoadConfig(strings.NewReader("kinds:\n  - kind: MAINTENANCE\n"))
+	require.ErrorContains(t, err, `unknown kind "MAINTENANCE"`)
+}
@@ -0,0 +1,28 @@
+package restyoops
+
+import "context"
+
+// Reporter sends failures to an error tracker, like Sentry
+// Reporter 将失败发送到错误追踪系统，如 Sentry
+type Reporter interface {
+	Report(ctx context.Context, oops *Oops)
+}
+
+// ShouldReport checks if the Oops is worth reporting: not retryable, or retryable but retries are exhausted
+// Transport and Attach mark the Oops as exhausted once they give up, see WithExhausted
+//
+// ShouldReport 检查 Oops 是否值得上报：不可重试，或可重试但重试已耗尽
+// Transport 和 Attach 在放弃时将 Oops 标记为已耗尽，见 WithExhausted
+func ShouldReport(oops *Oops) bool {
+	return !oops.Retryable || oops.Exhausted
+}
+
+// NewReportHook creates a Hook passing the failures matching ShouldReport to the reporter
+// NewReportHook 创建一个 Hook，将符合 ShouldReport 的失败传递给 reporter
+func NewReportHook(reporter Reporter) Hook {
+	return func(ctx context.Context, oops *Oops) {
+		if ShouldReport(oops) {
+			reporter.Report(ctx, oops)
+		}
+	}
+}
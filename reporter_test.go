@@ -0,0 +1,124 @@
+package restyoops_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// fakeReporter collects the reported failures
+// fakeReporter 收集上报的失败
+type fakeReporter struct {
+	reported []*restyoops.Oops
+}
+
+func (r *fakeReporter) Report(ctx context.Context, oops *restyoops.Oops) {
+	r.reported = append(r.reported, oops)
+}
+
+// TestShouldReport tests only non-retryable or exhausted failures are reported
+// TestShouldReport 测试只上报不可重试或重试已耗尽的失败
+func TestShouldReport(t *testing.T) {
+	busy := restyoops.NewOops(restyoops.KindHttp, 503, errors.New("busy"), true)
+	require.False(t, restyoops.ShouldReport(busy))
+
+	missing := restyoops.NewOops(restyoops.KindHttp, 404, errors.New("missing"), false)
+	require.True(t, restyoops.ShouldReport(missing))
+
+	busy.WithAttempts(restyoops.NewAttempt(busy, time.Millisecond), restyoops.NewAttempt(busy, time.Millisecond))
+	require.False(t, restyoops.ShouldReport(busy)) // history alone does not tell retries are over
+
+	require.True(t, restyoops.ShouldReport(busy.WithExhausted()))
+}
+
+// TestNewReportHook tests the hook reports the failure once the Transport gives up
+// TestNewReportHook 测试 Transport 放弃后钩子上报失败
+func TestNewReportHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reporter := &fakeReporter{}
+	detective := restyoops.NewDetective(restyoops.NewConfig().WithDefaultWait(time.Millisecond))
+	transport := restyoops.NewTransport(detective).
+		WithRetries(2).
+		WithHooks(restyoops.NewReportHook(reporter))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Len(t, reporter.reported, 1)
+	require.True(t, reporter.reported[0].Retryable)
+	require.True(t, reporter.reported[0].Exhausted)
+	require.Equal(t, "3 attempts: 503, 503, 503", reporter.reported[0].Summary())
+
+	// Retryable failure without history is still being retried
+	restyoops.NewReportHook(reporter)(context.Background(), restyoops.NewOops(restyoops.KindHttp, 503, errors.New("busy"), true))
+	require.Len(t, reporter.reported, 1)
+}
+
+// TestNewReportHook_NoRetries tests the Transport without retries reports the retryable failure at once
+// TestNewReportHook_NoRetries 测试不重试的 Transport 立即上报可重试的失败
+func TestNewReportHook_NoRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reporter := &fakeReporter{}
+	transport := restyoops.NewTransport(restyoops.NewDetective(restyoops.NewConfig())).
+		WithHooks(restyoops.NewReportHook(reporter))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Len(t, reporter.reported, 1)
+	require.True(t, reporter.reported[0].Retryable)
+	require.True(t, reporter.reported[0].Exhausted)
+	require.Empty(t, reporter.reported[0].Attempts)
+}
+
+// TestNewReportHook_Attach tests a resty client reports only the failure of its last attempt
+// TestNewReportHook_Attach 测试 resty 客户端只上报最后一次尝试的失败
+func TestNewReportHook_Attach(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reporter := &fakeReporter{}
+	client := resty.New().
+		SetRetryCount(2).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			return resp.StatusCode() == http.StatusServiceUnavailable
+		})
+	restyoops.NewDetective(restyoops.NewConfig()).Attach(client, restyoops.NewReportHook(reporter))
+
+	resp, err := client.R().Get(server.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	require.Equal(t, int32(3), count.Load())
+	require.Len(t, reporter.reported, 1)
+	require.True(t, reporter.reported[0].Exhausted)
+
+	// Without retries the first attempt is the last one
+	reporter.reported = nil
+	noRetry := restyoops.NewDetective(restyoops.NewConfig()).Attach(resty.New(), restyoops.NewReportHook(reporter))
+	_, err = noRetry.R().Get(server.URL)
+	require.NoError(t, err)
+	require.Len(t, reporter.reported, 1)
+}
@@ -0,0 +1,69 @@
+package restyoops
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces secret query values in captured URLs
+// redactedValue 替换捕获 URL 中的敏感查询参数值
+const redactedValue = "REDACTED"
+
+// captureRequest fills the Oops with request context from the exchange
+// captureRequest 从交换中提取请求上下文填充到 Oops
+func captureRequest(cfg *Config, oops *Oops, exchange *Exchange) {
+	if exchange.Method == "" && exchange.URL == nil {
+		return
+	}
+
+	oops.Method = exchange.Method
+	if exchange.URL != nil {
+		oops.URL = redactURL(cfg, exchange.URL)
+	}
+	oops.RequestID = findRequestID(cfg, exchange.RequestHeader)
+	oops.ResponseRequestID = findRequestID(cfg, exchange.Header)
+	if exchange.Duration > 0 {
+		oops.Duration = exchange.Duration
+	}
+	oops.RemoteAddr = exchange.RemoteAddr
+}
+
+// findRequestID returns the value of the first configured request-ID header found in the headers
+// findRequestID 返回在头中找到的第一个配置的请求 ID 头的值
+func findRequestID(cfg *Config, header http.Header) string {
+	for _, name := range cfg.RequestIDHeaders {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// redactURL hides the password and configured query values, keeping the param sequence
+// redactURL 隐藏密码和配置的查询参数值，保持参数顺序
+func redactURL(cfg *Config, u *url.URL) string {
+	clone := *u
+	if clone.RawQuery != "" {
+		parts := strings.Split(clone.RawQuery, "&")
+		for idx, part := range parts {
+			key, _, _ := strings.Cut(part, "=")
+			if name, err := url.QueryUnescape(key); err == nil && isRedactQueryKey(cfg, name) {
+				parts[idx] = key + "=" + redactedValue
+			}
+		}
+		clone.RawQuery = strings.Join(parts, "&")
+	}
+	return clone.Redacted()
+}
+
+// isRedactQueryKey checks if the query key is configured as secret, ignoring case
+// isRedactQueryKey 检查查询参数名是否配置为敏感，不区分大小写
+func isRedactQueryKey(cfg *Config, name string) bool {
+	for _, key := range cfg.RedactQueryKeys {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package restyoops_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestDetect_CaptureRequest tests Detect fills method, redacted URL, request ID and duration
+// TestDetect_CaptureRequest 测试 Detect 填充请求方法、脱敏 URL、请求 ID 和耗时
+func TestDetect_CaptureRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "resp-123")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := resty.New().EnableTrace()
+	resp, err := client.R().
+		SetHeader("X-Request-Id", "req-456").
+		SetQueryParam("page", "2").
+		SetQueryParam("token", "abc").
+		Post(server.URL + "/orders")
+
+	oops := restyoops.Detect(restyoops.NewConfig(), resp, err)
+	require.Equal(t, http.MethodPost, oops.Method)
+	require.True(t, strings.HasPrefix(oops.URL, server.URL+"/orders?"))
+	require.Contains(t, oops.URL, "page=2")
+	require.Contains(t, oops.URL, "token=REDACTED")
+	require.NotContains(t, oops.URL, "abc")
+	require.Equal(t, "req-456", oops.RequestID) // both kept when they differ
+	require.Equal(t, "resp-123", oops.ResponseRequestID)
+	require.Positive(t, oops.Duration)
+	require.NotEmpty(t, oops.RemoteAddr)
+}
+
+// TestDetect_CaptureRequest_Config tests custom request-ID headers and redact keys
+// TestDetect_CaptureRequest_Config 测试自定义请求 ID 头和脱敏参数名
+func TestDetect_CaptureRequest_Config(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	resp, err := resty.New().R().
+		SetHeader("X-Trace-Id", "trace-789").
+		SetQueryParam("session", "s3cr3t").
+		SetQueryParam("token", "visible").
+		Get(server.URL)
+
+	cfg := restyoops.NewConfig().
+		WithRequestIDHeaders("X-Trace-Id").
+		WithRedactQueryKeys("Session")
+	oops := restyoops.Detect(cfg, resp, err)
+	require.Equal(t, http.MethodGet, oops.Method)
+	require.Contains(t, oops.URL, "session=REDACTED")
+	require.Contains(t, oops.URL, "token=visible")
+	require.Equal(t, "trace-789", oops.RequestID)
+	require.Empty(t, oops.ResponseRequestID) // not returned by the server
+	require.Empty(t, oops.RemoteAddr)        // trace not enabled
+}
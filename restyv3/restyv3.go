@@ -0,0 +1,196 @@
+// Package restyv3: restyoops classification of resty.dev/v3 responses
+// Shares Config, Kind and Oops with the root package, so both resty versions classify identically
+//
+// restyv3: 对 resty.dev/v3 响应进行 restyoops 分类
+// 与根包共享 Config、Kind 和 Oops，因此两个 resty 版本的分类结果一致
+package restyv3
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/yyle88/must"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/internal/utils"
+	"resty.dev/v3"
+)
+
+// Detect classifies a resty v3 response
+// Detect 分类 resty v3 响应
+func Detect(cfg *restyoops.Config, resp *resty.Response, respCause error) *restyoops.Oops {
+	return restyoops.DetectExchange(cfg, NewExchange(resp), respCause)
+}
+
+// DetectExplain classifies a resty v3 response like Detect, and explains how the outcome was decided
+// DetectExplain 与 Detect 一样分类 resty v3 响应，并解释结果是如何决定的
+func DetectExplain(cfg *restyoops.Config, resp *resty.Response, respCause error) (*restyoops.Oops, *restyoops.Explanation) {
+	return restyoops.DetectExchangeExplain(cfg, NewExchange(resp), respCause)
+}
+
+// NewExchange converts a resty v3 response into an Exchange, nil response gives nil
+// NewExchange 将 resty v3 响应转换为 Exchange，响应为 nil 时返回 nil
+func NewExchange(resp *resty.Response) *restyoops.Exchange {
+	if resp == nil {
+		return nil
+	}
+	exchange := &restyoops.Exchange{
+		StatusCode: resp.StatusCode(),
+		Header:     resp.Header(),
+	}
+	if resp.RawResponse != nil {
+		exchange.Body = resp.Bytes()
+	}
+	if req := resp.Request; req != nil {
+		exchange.Method = req.Method
+		exchange.URL = requestURL(req)
+		exchange.RequestHeader = req.Header
+		exchange.Duration = resp.Duration()
+		exchange.RemoteAddr = req.TraceInfo().RemoteAddr
+	}
+	return exchange
+}
+
+// Select returns the Config of the Detective used with the request, nil request selects the default Config
+// Select 返回 Detective 用于该请求的 Config，请求为 nil 时选择默认 Config
+func Select(detective *restyoops.Detective, req *resty.Request) *restyoops.Config {
+	if req == nil {
+		return detective.SelectURL("", nil)
+	}
+	return detective.SelectURL(req.Method, requestURL(req))
+}
+
+// DetectWith classifies a resty v3 response with the Detective and returns both response and oops issue
+// DetectWith 使用 Detective 分类 resty v3 响应并返回响应和 oops 问题
+func DetectWith(detective *restyoops.Detective, resp *resty.Response, respCause error) (*resty.Response, *restyoops.OopsIssue) {
+	var req *resty.Request
+	if resp != nil {
+		req = resp.Request
+	}
+	oops := Detect(Select(detective, req), resp, respCause)
+	if oops != nil {
+		must.Nice(oops.Kind)
+		must.Wrong(oops.Cause)
+	}
+	return resp, oops
+}
+
+// Attach registers the hooks on the client, so each failed attempt is classified and passed to them
+// Responses are classified once in a response middleware, transport failures when seen by a retry hook or on giving up
+// Call it after SetTransport, since it wraps the transport of the client to keep the cause of transport failures
+// Failures followed by a retry reach the hooks from a retry hook, the final failure is marked as exhausted
+// and carries the attempt history when retried
+//
+// Attach 在客户端上注册钩子，每次失败的尝试都会被分类并传递给钩子
+// 响应在响应中间件中只分类一次，传输失败在重试钩子中或最终放弃时分类
+// 需在 SetTransport 之后调用，因为它会包装客户端的传输以保留传输失败的原因
+// 之后会重试的失败由重试钩子传递给钩子，最终失败会被标记为已耗尽，重试过时附带尝试历史
+func Attach(detective *restyoops.Detective, client *resty.Client, hooks ...restyoops.Hook) *resty.Client {
+	must.Full(detective)
+	tracker := restyoops.NewTracker[*resty.Request]()
+	client.SetTransport(&causeTransport{base: client.Transport()})
+	client.AddRequestMiddleware(func(_ *resty.Client, req *resty.Request) error {
+		if slot, ok := req.Context().Value(causeKey{}).(*causeSlot); ok {
+			slot.cause = nil // a new attempt of the same request
+			return nil
+		}
+		req.SetContext(context.WithValue(req.Context(), causeKey{}, &causeSlot{}))
+		return nil
+	})
+	client.AddResponseMiddleware(func(_ *resty.Client, resp *resty.Response) error {
+		tracker.SetPending(resp.Request, Detect(Select(detective, resp.Request), resp, nil))
+		return nil
+	})
+	client.AddRetryHooks(func(resp *resty.Response, respCause error) {
+		if resp == nil || resp.Request == nil {
+			return // failed before sending, no request to track
+		}
+		req := resp.Request
+		oops := tracker.TakePending(req)
+		if oops == nil && resp.RawResponse == nil {
+			if respCause == nil {
+				respCause = transportCause(req) // resty passes no cause to retry hooks after transport failures
+			}
+			if respCause != nil {
+				oops = Detect(Select(detective, req), resp, respCause)
+			}
+		}
+		if oops != nil {
+			tracker.AddAttempt(req, restyoops.NewAttempt(oops, resp.Duration()))
+			restyoops.RunHooks(req.Context(), oops, hooks)
+		}
+	})
+	client.OnSuccess(func(_ *resty.Client, resp *resty.Response) {
+		if oops := tracker.TakePending(resp.Request); oops != nil {
+			restyoops.RunHooks(resp.Request.Context(), tracker.Finish(resp.Request, oops, resp.Duration()), hooks)
+		}
+		tracker.Forget(resp.Request)
+	})
+	client.OnError(func(req *resty.Request, respCause error) {
+		var resp *resty.Response
+		if respErr, ok := utils.ErrorsAs[*resty.ResponseError](respCause); ok {
+			resp, respCause = respErr.Response, respErr.Err
+		}
+		oops := tracker.TakePending(req) // a response was received and classified
+		if oops == nil {
+			oops = Detect(Select(detective, req), resp, respCause)
+		}
+		if oops != nil {
+			var duration time.Duration
+			if resp != nil {
+				duration = resp.Duration()
+			}
+			restyoops.RunHooks(req.Context(), tracker.Finish(req, oops, duration), hooks)
+		}
+		tracker.Forget(req)
+	})
+	return client
+}
+
+// causeKey is the context key of the causeSlot of a request
+// causeKey 是请求 causeSlot 的上下文键
+type causeKey struct{}
+
+// causeSlot holds the transport failure of the current attempt
+// causeSlot 保存当前尝试的传输失败
+type causeSlot struct {
+	cause error
+}
+
+// causeTransport records transport failures into the causeSlot of the request context
+// causeTransport 将传输失败记录到请求上下文的 causeSlot 中
+type causeTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request through the base RoundTripper, recording the failure
+// RoundTrip 通过底层 RoundTripper 发送请求并记录失败
+func (t *causeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if slot, ok := req.Context().Value(causeKey{}).(*causeSlot); ok && err != nil {
+		slot.cause = err
+	}
+	return resp, err
+}
+
+// transportCause returns the transport failure of the current attempt of the request, nil when none
+// transportCause 返回请求当前尝试的传输失败，没有时返回 nil
+func transportCause(req *resty.Request) error {
+	if slot, ok := req.Context().Value(causeKey{}).(*causeSlot); ok {
+		return slot.cause
+	}
+	return nil
+}
+
+// requestURL returns the sent URL of the request, falling back to the parsed URL
+// requestURL 返回请求实际发送的 URL，缺失时回退到解析后的 URL
+func requestURL(req *resty.Request) *url.URL {
+	if req.RawRequest != nil && req.RawRequest.URL != nil {
+		return req.RawRequest.URL
+	}
+	if u, err := url.Parse(req.URL); err == nil {
+		return u
+	}
+	return nil
+}
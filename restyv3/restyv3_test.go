@@ -0,0 +1,154 @@
+package restyv3_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	restyv2 "github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+	"github.com/yyle88/restyoops/internal/matrix"
+	"github.com/yyle88/restyoops/restyv3"
+	"resty.dev/v3"
+)
+
+// TestMatrix tests resty v3 responses against the shared cases, and compares them with resty v2
+// TestMatrix 测试 resty v3 响应在共享用例上的结果，并与 resty v2 对比
+func TestMatrix(t *testing.T) {
+	for _, tc := range matrix.Cases() {
+		t.Run(tc.Name, func(t *testing.T) {
+			serverURL, stop := tc.Serve()
+			defer stop()
+
+			client := resty.New().SetTimeout(tc.Timeout)
+			defer func() { require.NoError(t, client.Close()) }()
+			resp, err := client.R().Get(serverURL)
+			oops := restyv3.Detect(tc.NewConfig(), resp, err)
+
+			respV2, errV2 := restyv2.New().SetTimeout(tc.Timeout).R().Get(serverURL)
+			expected := restyoops.Detect(tc.NewConfig(), respV2, errV2)
+
+			if tc.Kind == "" {
+				require.Nil(t, oops)
+				require.Nil(t, expected)
+				return
+			}
+			require.Equal(t, tc.Kind, oops.Kind)
+			require.Equal(t, tc.Retryable, oops.Retryable)
+			require.Equal(t, tc.Reason, oops.Reason)
+
+			require.Equal(t, expected.Kind, oops.Kind)
+			require.Equal(t, expected.StatusCode, oops.StatusCode)
+			require.Equal(t, expected.Retryable, oops.Retryable)
+			require.Equal(t, expected.WaitTime, oops.WaitTime)
+			require.Equal(t, expected.Reason, oops.Reason)
+			require.Equal(t, expected.Source, oops.Source)
+			require.Equal(t, expected.Method, oops.Method)
+			require.Equal(t, expected.URL, oops.URL)
+		})
+	}
+}
+
+// TestAttach tests the middleware and error hook pass each failure to the hooks
+// TestAttach 测试中间件和错误钩子将每个失败传递给钩子
+func TestAttach(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var hooked []*restyoops.Oops
+	detective := restyoops.NewDetective(restyoops.NewConfig())
+	client := restyv3.Attach(detective, resty.New(), func(ctx context.Context, oops *restyoops.Oops) {
+		hooked = append(hooked, oops)
+	})
+	defer func() { require.NoError(t, client.Close()) }()
+
+	_, err := client.R().Get(server.URL + "/ok")
+	require.NoError(t, err)
+	require.Empty(t, hooked)
+
+	_, err = client.R().Get(server.URL + "/busy")
+	require.NoError(t, err)
+	require.Len(t, hooked, 1)
+	require.Equal(t, http.StatusServiceUnavailable, hooked[0].StatusCode)
+	require.Equal(t, http.MethodGet, hooked[0].Method)
+	require.True(t, hooked[0].Exhausted) // no retries configured
+
+	server.Close()
+	_, err = client.R().Get(server.URL + "/gone")
+	require.Error(t, err)
+	require.Len(t, hooked, 2)
+	require.Equal(t, restyoops.KindNetwork, hooked[1].Kind)
+}
+
+// TestDetectWith tests the routes of the Detective apply to resty v3 requests
+// TestDetectWith 测试 Detective 的路由对 resty v3 请求生效
+func TestDetectWith(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	detective := restyoops.NewDetective(restyoops.NewConfig()).
+		WithRoute(restyoops.Route{Path: "/retry/"}, restyoops.NewConfig().WithStatusRetryable(http.StatusForbidden, true, 0))
+	client := resty.New()
+	defer func() { require.NoError(t, client.Close()) }()
+
+	resp, err := client.R().Get(server.URL + "/retry/x")
+	require.NoError(t, err)
+	_, oops := restyv3.DetectWith(detective, resp, err)
+	require.True(t, oops.Retryable)
+
+	resp, err = client.R().Get(server.URL + "/other")
+	require.NoError(t, err)
+	_, oops = restyv3.DetectWith(detective, resp, err)
+	require.False(t, oops.Retryable)
+}
+
+// TestAttach_Retries tests failures followed by a retry reach the hooks, and the final one carries the history
+// TestAttach_Retries 测试之后会重试的失败传递给钩子，最终失败附带尝试历史
+func TestAttach_Retries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var hooked []*restyoops.Oops
+	detective := restyoops.NewDetective(restyoops.NewConfig())
+	client := resty.New().
+		SetRetryCount(2).
+		SetRetryWaitTime(time.Millisecond).
+		AddRetryConditions(func(resp *resty.Response, respCause error) bool {
+			return respCause != nil // resty does not retry refused connections by default
+		})
+	client = restyv3.Attach(detective, client, func(ctx context.Context, oops *restyoops.Oops) {
+		hooked = append(hooked, oops)
+	})
+	defer func() { require.NoError(t, client.Close()) }()
+
+	_, err := client.R().Get(server.URL)
+	require.NoError(t, err)
+	require.Len(t, hooked, 3) // one per attempt
+	require.False(t, hooked[0].Exhausted)
+	require.False(t, hooked[1].Exhausted)
+	require.True(t, hooked[2].Exhausted)
+	require.Equal(t, "3 attempts: 503, 503, 503", hooked[2].Summary())
+
+	server.Close()
+	hooked = nil
+	_, err = client.R().Get(server.URL)
+	require.Error(t, err)
+	require.Len(t, hooked, 3) // transport failures are seen by the retry hook too
+	require.Equal(t, restyoops.KindNetwork, hooked[0].Kind)
+	require.Equal(t, restyoops.ReasonConnection, hooked[0].Reason)
+	require.False(t, hooked[0].Exhausted)
+	require.True(t, hooked[2].Exhausted)
+	require.Len(t, hooked[2].Attempts, 3)
+}
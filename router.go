@@ -0,0 +1,123 @@
+package restyoops
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/yyle88/must"
+)
+
+// Route matches requests by host, path and method, empty fields match any
+// Route 按主机、路径和方法匹配请求，空字段匹配任意值
+type Route struct {
+	Host   string // host like "api.example.com", or "*.example.com" matching subdomains // 主机，如 "api.example.com"，或匹配子域名的 "*.example.com"
+	Path   string // path prefix like "/v1/pay/", or path.Match pattern when containing *?[ // 路径前缀如 "/v1/pay/"，包含 *?[ 时为 path.Match 模式
+	Method string // method like "POST" // 方法，如 "POST"
+}
+
+// Match checks if the request matches the route
+// Match 检查请求是否匹配该路由
+func (r *Route) Match(method string, host string, urlPath string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if r.Host != "" && !matchHost(r.Host, host) {
+		return false
+	}
+	if r.Path != "" && !matchPath(r.Path, urlPath) {
+		return false
+	}
+	return true
+}
+
+// routeEntry pairs a route with its Config
+// routeEntry 将路由与其 Config 配对
+type routeEntry struct {
+	route *Route
+	cfg   *Config
+}
+
+// WithRoute adds a route using the Config, routes are checked in the sequence added and the first match wins
+// Requests matching no route use the default Config, the route keeps a frozen copy of the Config
+//
+// WithRoute 添加使用该 Config 的路由，按添加顺序检查，第一个匹配的生效
+// 不匹配任何路由的请求使用默认 Config，路由保存该 Config 的冻结拷贝
+func (c *Detective) WithRoute(route Route, cfg *Config) *Detective {
+	must.Done(cfg.Validate())
+	c.routesMutex.Lock()
+	defer c.routesMutex.Unlock()
+
+	var entries []*routeEntry
+	if current := c.routes.Load(); current != nil {
+		entries = append(entries, *current...)
+	}
+	entries = append(entries, &routeEntry{route: &route, cfg: cfg.Clone().Freeze()})
+	c.routes.Store(&entries)
+	return c
+}
+
+// Select returns the Config used with the request, nil request selects the default Config
+// Select 返回请求使用的 Config，请求为 nil 时选择默认 Config
+func (c *Detective) Select(req *resty.Request) *Config {
+	if req == nil {
+		return c.SelectURL("", nil)
+	}
+	return c.SelectURL(req.Method, restyRequestURL(req))
+}
+
+// SelectHTTP returns the Config used with the net/http request, nil request selects the default Config
+// SelectHTTP 返回 net/http 请求使用的 Config，请求为 nil 时选择默认 Config
+func (c *Detective) SelectHTTP(req *http.Request) *Config {
+	if req == nil {
+		return c.SelectURL("", nil)
+	}
+	return c.SelectURL(req.Method, req.URL)
+}
+
+// SelectURL returns the Config of the first route matching the method and URL, nil URL selects the default Config
+// SelectURL 返回第一个匹配方法和 URL 的路由的 Config，URL 为 nil 时选择默认 Config
+func (c *Detective) SelectURL(method string, u *url.URL) *Config {
+	entries := c.routes.Load()
+	if u == nil || entries == nil {
+		return c.cfg.Load()
+	}
+	for _, entry := range *entries {
+		if entry.route.Match(method, u.Hostname(), u.Path) {
+			return entry.cfg
+		}
+	}
+	return c.cfg.Load()
+}
+
+// selectResponse returns the Config used with the response
+// selectResponse 返回响应使用的 Config
+func (c *Detective) selectResponse(resp *resty.Response) *Config {
+	if resp == nil {
+		return c.Select(nil)
+	}
+	return c.Select(resp.Request)
+}
+
+// matchHost matches the host exactly or by "*." wildcard, ignoring case
+// matchHost 精确匹配主机或通过 "*." 通配匹配，不区分大小写
+func matchHost(pattern string, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		host = strings.ToLower(host)
+		suffix = strings.ToLower(suffix)
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return strings.EqualFold(pattern, host)
+}
+
+// matchPath matches the path by prefix, or by path.Match when the pattern has wildcards
+// matchPath 按前缀匹配路径，模式包含通配符时使用 path.Match
+func matchPath(pattern string, urlPath string) bool {
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, urlPath)
+		return err == nil && matched
+	}
+	return strings.HasPrefix(urlPath, pattern)
+}
@@ -0,0 +1,76 @@
+package restyoops_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestRoute_Match tests host, path and method matching
+// TestRoute_Match 测试主机、路径和方法的匹配
+func TestRoute_Match(t *testing.T) {
+	route := &restyoops.Route{Host: "*.example.com", Path: "/v1/pay/", Method: "post"}
+	require.True(t, route.Match(http.MethodPost, "api.EXAMPLE.com", "/v1/pay/orders"))
+	require.False(t, route.Match(http.MethodGet, "api.example.com", "/v1/pay/orders"))
+	require.False(t, route.Match(http.MethodPost, "example.com", "/v1/pay/orders"))
+	require.False(t, route.Match(http.MethodPost, "api.example.com", "/v1/search"))
+
+	pattern := &restyoops.Route{Host: "search.example.com", Path: "/v*/search"}
+	require.True(t, pattern.Match(http.MethodGet, "search.example.com", "/v2/search"))
+	require.False(t, pattern.Match(http.MethodGet, "search.example.com", "/v2/search/more"))
+
+	require.True(t, (&restyoops.Route{}).Match(http.MethodDelete, "any.host", "/"))
+}
+
+// TestDetective_WithRoute tests one Detective applies different Config per route
+// TestDetective_WithRoute 测试同一个 Detective 按路由使用不同的 Config
+func TestDetective_WithRoute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	detective := restyoops.NewDetective(restyoops.NewConfig()).
+		WithRoute(restyoops.Route{Path: "/pay/", Method: http.MethodPost}, restyoops.NewConfig().WithStatusRetryable(500, false, 0)).
+		WithRoute(restyoops.Route{Path: "/pay/"}, restyoops.NewConfig().WithStatusRetryable(500, true, 0))
+
+	client := resty.New()
+	_, oops := detective.Detect(client.R().Post(server.URL + "/pay/orders"))
+	require.False(t, oops.Retryable) // first matching route wins
+
+	_, oops = detective.Detect(client.R().Get(server.URL + "/pay/orders"))
+	require.True(t, oops.Retryable)
+
+	_, oops = detective.Detect(client.R().Post(server.URL + "/search"))
+	require.True(t, oops.Retryable) // default config
+
+	_, oops = detective.Detect(nil, context.DeadlineExceeded)
+	require.True(t, oops.Retryable) // no request, default config
+}
+
+// TestDetective_WithRoute_Attach tests hooks use the Config selected by route
+// TestDetective_WithRoute_Attach 测试钩子使用按路由选择的 Config
+func TestDetective_WithRoute_Attach(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	detective := restyoops.NewDetective(restyoops.NewConfig()).
+		WithRoute(restyoops.Route{Path: "/pay/"}, restyoops.NewConfig().WithStatusRetryable(500, false, 0))
+
+	var issues []*restyoops.Oops
+	client := detective.Attach(resty.New(), func(ctx context.Context, oops *restyoops.Oops) {
+		issues = append(issues, oops)
+	})
+	_, _ = client.R().Get(server.URL + "/pay/refund")
+	_, _ = client.R().Get(server.URL + "/search")
+	require.Len(t, issues, 2)
+	require.False(t, issues[0].Retryable)
+	require.True(t, issues[1].Retryable)
+}
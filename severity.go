@@ -0,0 +1,81 @@
+package restyoops
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// Severity tells how urgent an Oops is, so alerting rules can rely on it
+// Severity 表示 Oops 的紧急程度，供告警规则使用
+type Severity string
+
+const (
+	SeverityDebug    Severity = "debug"    // Expected outcome, kept for troubleshooting // 预期内的结果，仅用于排查
+	SeverityInfo     Severity = "info"     // Expected outcome worth counting // 值得统计的预期结果
+	SeverityWarn     Severity = "warn"     // Degraded but handled // 有降级但已处理
+	SeverityError    Severity = "error"    // Failure needing a look // 需要关注的失败
+	SeverityCritical Severity = "critical" // Failure needing a page // 需要立即告警的失败
+)
+
+// severities lists the severities from low to high
+// severities 按从低到高列出严重程度
+var severities = []Severity{SeverityDebug, SeverityInfo, SeverityWarn, SeverityError, SeverityCritical}
+
+// String returns the string representation of Severity
+// String 返回 Severity 的字符串表示
+func (s Severity) String() string {
+	return string(s)
+}
+
+// IsValid checks if the Severity is one of the five levels
+// IsValid 检查 Severity 是否为五个级别之一
+func (s Severity) IsValid() bool {
+	return slices.Contains(severities, s)
+}
+
+// AtLeast checks if the Severity is as high as the other one, invalid values rank lowest
+// AtLeast 检查 Severity 是否不低于另一个，无效值排在最低
+func (s Severity) AtLeast(other Severity) bool {
+	return slices.Index(severities, s) >= slices.Index(severities, other)
+}
+
+// Level returns the slog level of the Severity, critical is above error
+// Level 返回 Severity 对应的 slog 级别，critical 高于 error
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityError:
+		return slog.LevelError
+	case SeverityCritical:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// severityOf picks the Severity of the Oops by business code, reason, status code, then Kind
+// Falls back to the severity registered with the Kind, then warn
+//
+// severityOf 依次按业务码、原因、状态码、Kind 选择 Oops 的 Severity
+// 未配置时回退到 Kind 注册的严重程度，最后为 warn
+func severityOf(cfg *Config, oops *Oops) Severity {
+	if severity, ok := cfg.BusinessCodeSeverities[oops.BusinessCode]; ok && oops.BusinessCode != "" {
+		return severity
+	}
+	if severity, ok := cfg.ReasonSeverities[oops.Reason]; ok && oops.Reason != "" {
+		return severity
+	}
+	if severity, ok := cfg.StatusSeverities[oops.StatusCode]; ok && oops.StatusCode > 0 {
+		return severity
+	}
+	if severity, ok := cfg.KindSeverities[oops.Kind]; ok {
+		return severity
+	}
+	if info, ok := LookupKind(oops.Kind); ok && info.Severity.IsValid() {
+		return info.Severity
+	}
+	return SeverityWarn
+}
@@ -0,0 +1,99 @@
+package restyoops_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestSeverity tests severities are picked by business code, reason, status, then Kind
+// TestSeverity 测试严重程度依次按业务码、原因、状态码、Kind 选择
+func TestSeverity(t *testing.T) {
+	cfg := restyoops.NewConfig().
+		WithKindSeverity(restyoops.KindBlock, restyoops.SeverityCritical).
+		WithStatusSeverity(http.StatusNotFound, restyoops.SeverityDebug).
+		WithReasonSeverity("service_unavailable", restyoops.SeverityError).
+		WithBusinessCodeSeverity("1001", restyoops.SeverityInfo).
+		WithContentCheck(http.StatusOK, func(contentType string, content []byte) *restyoops.Oops {
+			switch {
+			case bytes.Contains(content, []byte("captcha")):
+				return restyoops.NewOops(restyoops.KindBlock, http.StatusOK, errors.New("captcha"), true)
+			case bytes.Contains(content, []byte("1001")):
+				return restyoops.NewOops(restyoops.KindBusiness, http.StatusOK, errors.New("code 1001"), false).WithBusinessCode("1001")
+			case bytes.Contains(content, []byte("fraud")):
+				return restyoops.NewOops(restyoops.KindBusiness, http.StatusOK, errors.New("fraud"), false).WithSeverity(restyoops.SeverityCritical)
+			}
+			return nil
+		})
+
+	require.Equal(t, restyoops.SeverityDebug, detectServed(t, cfg, http.StatusNotFound, "").Severity)
+	require.Equal(t, restyoops.SeverityError, detectServed(t, cfg, http.StatusServiceUnavailable, "").Severity)
+	require.Equal(t, restyoops.SeverityWarn, detectServed(t, cfg, http.StatusBadGateway, "").Severity)
+	require.Equal(t, restyoops.SeverityCritical, detectServed(t, cfg, http.StatusOK, "captcha").Severity)
+	require.Equal(t, restyoops.SeverityInfo, detectServed(t, cfg, http.StatusOK, `{"code":1001}`).Severity)
+	require.Equal(t, restyoops.SeverityCritical, detectServed(t, cfg, http.StatusOK, "fraud").Severity)
+
+	// Kinds without settings fall back to the registered severity
+	// 未配置的 Kind 回退到注册的严重程度
+	oops := restyoops.Detect(restyoops.NewConfig(), nil, errors.New("boom"))
+	require.Equal(t, restyoops.KindUnknown, oops.Kind)
+	require.Equal(t, restyoops.SeverityError, oops.Severity)
+
+	require.True(t, restyoops.SeverityCritical.AtLeast(restyoops.SeverityError))
+	require.False(t, restyoops.SeverityInfo.AtLeast(restyoops.SeverityWarn))
+	require.Equal(t, slog.LevelDebug, restyoops.SeverityDebug.Level())
+	require.Greater(t, restyoops.SeverityCritical.Level(), slog.LevelError)
+
+	require.Error(t, restyoops.NewConfig().WithStatusSeverity(http.StatusNotFound, "fatal").Validate())
+}
+
+// TestSeverityLoadConfig tests severity rules from config files, with business codes of json_path checks
+// TestSeverityLoadConfig 测试配置文件中的严重程度规则，以及 json_path 检查的业务码
+func TestSeverityLoadConfig(t *testing.T) {
+	cfg, err := restyoops.LoadConfig(strings.NewReader(`
+content_checks:
+  - status: 200
+    json_path: code
+    success: [0]
+severities:
+  - status: 404
+    severity: debug
+  - business_code: "2002"
+    severity: critical
+`))
+	require.NoError(t, err)
+
+	oops := detectServed(t, cfg, http.StatusOK, `{"code":2002}`)
+	require.Equal(t, "2002", oops.BusinessCode)
+	require.Equal(t, restyoops.SeverityCritical, oops.Severity)
+	require.Equal(t, restyoops.SeverityInfo, detectServed(t, cfg, http.StatusOK, `{"code":1}`).Severity)
+	require.Equal(t, restyoops.SeverityDebug, detectServed(t, cfg, http.StatusNotFound, "").Severity)
+
+	_, err = restyoops.LoadConfig(strings.NewReader("severities:\n  - status: 404\n    reason: not_found\n    severity: debug\n  - kind: HTTP\n    severity: fatal\n"))
+	require.ErrorContains(t, err, "severities[0]")
+	require.ErrorContains(t, err, "exactly one of kind")
+	require.ErrorContains(t, err, `unknown severity "fatal"`)
+}
+
+// TestNewSlogHookSeverity tests the hook logs at the level of the severity without a Kind level
+// TestNewSlogHookSeverity 测试未配置 Kind 级别时钩子按严重程度的级别记录
+func TestNewSlogHookSeverity(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg := restyoops.NewConfig().WithStatusSeverity(http.StatusNotFound, restyoops.SeverityDebug)
+
+	oops := detectServed(t, cfg, http.StatusNotFound, "")
+	restyoops.NewSlogHook(cfg, logger)(t.Context(), oops)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "DEBUG", record["level"])
+	require.Equal(t, "debug", record["oops"].(map[string]any)["severity"])
+}
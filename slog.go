@@ -0,0 +1,66 @@
+package restyoops
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogValue renders the Oops as a slog group, implementing slog.LogValuer
+// LogValue 将 Oops 渲染为 slog 分组，实现 slog.LogValuer
+func (o *Oops) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("kind", o.Kind.String()),
+		slog.Int("status", o.StatusCode),
+		slog.Bool("retryable", o.Retryable),
+		slog.Duration("wait", o.WaitTime),
+	}
+	if o.Reason != "" {
+		attrs = append(attrs, slog.String("reason", o.Reason))
+	}
+	if o.Source != "" {
+		attrs = append(attrs, slog.String("source", string(o.Source)))
+	}
+	if o.Severity != "" {
+		attrs = append(attrs, slog.String("severity", o.Severity.String()))
+	}
+	if o.BusinessCode != "" {
+		attrs = append(attrs, slog.String("business_code", o.BusinessCode))
+	}
+	if o.Cause != nil {
+		attrs = append(attrs, slog.String("cause", o.Cause.Error()))
+	}
+	if o.Method != "" {
+		attrs = append(attrs, slog.String("method", o.Method))
+	}
+	if o.URL != "" {
+		attrs = append(attrs, slog.String("url", o.URL))
+	}
+	if o.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", o.RequestID))
+	}
+	if o.ResponseRequestID != "" {
+		attrs = append(attrs, slog.String("response_request_id", o.ResponseRequestID))
+	}
+	attrs = append(attrs, slog.String("fingerprint", o.Fingerprint()))
+	if len(o.Attempts) > 0 {
+		attrs = append(attrs, slog.String("attempts", o.Summary()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// NewSlogHook creates a Hook logging each failure at the level configured with its Kind
+// Without a Kind level, the level follows the Severity, then DefaultLogLevel when Severity is not set
+//
+// NewSlogHook 创建一个 Hook，按 Kind 配置的级别记录每个失败
+// 未配置 Kind 级别时按 Severity 选择级别，Severity 未设置时使用 DefaultLogLevel
+func NewSlogHook(cfg *Config, logger *slog.Logger) Hook {
+	return func(ctx context.Context, oops *Oops) {
+		level := cfg.DefaultLogLevel
+		if kindLevel, ok := cfg.LogLevels[oops.Kind]; ok {
+			level = kindLevel
+		} else if oops.Severity != "" {
+			level = oops.Severity.Level()
+		}
+		logger.LogAttrs(ctx, level, "restyoops", slog.Any("oops", oops))
+	}
+}
@@ -0,0 +1,73 @@
+package restyoops_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestOops_LogValue tests Oops renders as a slog group with classification attrs
+// TestOops_LogValue 测试 Oops 渲染为包含分类属性的 slog 分组
+func TestOops_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	oops := restyoops.NewOops(restyoops.KindBlock, http.StatusForbidden, errors.New("captcha page"), true).
+		WithWaitTime(5 * time.Second).
+		WithReason("captcha")
+	logger.Info("request failed", "oops", oops)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	group := record["oops"].(map[string]any)
+	require.Equal(t, "BLOCK", group["kind"])
+	require.Equal(t, float64(403), group["status"])
+	require.Equal(t, true, group["retryable"])
+	require.Equal(t, float64(5*time.Second), group["wait"])
+	require.Equal(t, "captcha", group["reason"])
+	require.Equal(t, "captcha page", group["cause"])
+}
+
+// TestNewSlogHook tests the hook logs failures at the level configured per Kind
+// TestNewSlogHook 测试钩子按 Kind 配置的级别记录失败
+func TestNewSlogHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	cfg := restyoops.NewConfig().WithLogLevel(restyoops.KindHttp, slog.LevelError)
+	client := restyoops.NewDetective(cfg).Attach(resty.New(), restyoops.NewSlogHook(cfg, logger))
+
+	_, err := client.R().Get(server.URL + "/ok")
+	require.NoError(t, err)
+	require.Zero(t, buf.Len()) // success not logged
+
+	_, err = client.R().Get(server.URL + "/missing")
+	require.NoError(t, err)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "ERROR", record["level"])
+	group := record["oops"].(map[string]any)
+	require.Equal(t, "HTTP", group["kind"])
+	require.Equal(t, "not_found", group["reason"])
+	require.Equal(t, "default", group["source"])
+	require.Equal(t, http.MethodGet, group["method"])
+}
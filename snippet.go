@@ -0,0 +1,194 @@
+package restyoops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetSniffSize is the count of leading bytes inspected in binary detection
+// snippetSniffSize 是二进制检测时检查的前导字节数
+const snippetSniffSize = 512
+
+// snippetDecodeLimit is the max body size decoded as JSON for redaction, larger bodies are redacted as text only
+// snippetDecodeLimit 是为脱敏按 JSON 解码的最大响应体大小，更大的响应体只按文本脱敏
+const snippetDecodeLimit = 64 << 10
+
+// binaryContentTypes lists content type prefixes treated as binary
+// binaryContentTypes 列出视为二进制的内容类型前缀
+var binaryContentTypes = []string{
+	"image/",
+	"audio/",
+	"video/",
+	"font/",
+	"application/octet-stream",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"application/x-protobuf",
+	"application/protobuf",
+}
+
+// makeSnippet builds a size-capped, redacted excerpt of the response body
+// makeSnippet 构建限制大小且已脱敏的响应体摘录
+func makeSnippet(cfg *Config, contentType string, content []byte) string {
+	if cfg.SnippetLimit <= 0 || len(content) == 0 {
+		return ""
+	}
+	if isBinaryContent(contentType, content) {
+		return fmt.Sprintf("<binary %d bytes>", len(content))
+	}
+	if len(content) > snippetDecodeLimit {
+		// Decoding is skipped, the kept part is redacted as text
+		// 跳过解码，保留的部分按文本脱敏
+		cut := cutUTF8(content, cfg.SnippetLimit)
+		return fmt.Sprintf("%s...(%d bytes total)", redactText(cfg, content[:cut]), len(content))
+	}
+	return truncateUTF8(RedactBody(cfg, content), cfg.SnippetLimit)
+}
+
+// RedactBody replaces values of the RedactFields of the Config in the body
+// JSON bodies are decoded, other bodies like form-encoded ones are redacted as text
+//
+// RedactBody 替换响应体中 Config 的 RedactFields 字段的值
+// JSON 响应体会被解码，表单编码等其它响应体按文本脱敏
+func RedactBody(cfg *Config, content []byte) []byte {
+	if redacted, ok := redactJSON(cfg, content); ok {
+		return redacted
+	}
+	return redactText(cfg, content)
+}
+
+// isBinaryContent checks content type and leading bytes to detect binary body
+// isBinaryContent 通过内容类型和前导字节检测二进制响应体
+func isBinaryContent(contentType string, content []byte) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(contentType))
+	for _, prefix := range binaryContentTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+
+	head := content
+	if len(head) > snippetSniffSize {
+		head = head[:snippetSniffSize]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	for idx := 0; idx < len(head); {
+		r, size := utf8.DecodeRune(head[idx:])
+		if r == utf8.RuneError && size == 1 {
+			// The sniff window may split a rune at the end
+			// 检查窗口可能在末尾截断一个字符
+			return len(head) == len(content) || len(head)-idx >= utf8.UTFMax
+		}
+		idx += size
+	}
+	return false
+}
+
+// redactJSON replaces values of configured fields at any depth, returns false when not JSON or nothing redacted
+// redactJSON 替换任意层级中配置字段的值，非 JSON 或无需脱敏时返回 false
+func redactJSON(cfg *Config, content []byte) ([]byte, bool) {
+	if len(cfg.RedactFields) == 0 || !json.Valid(content) {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	if !redactValue(cfg, value) {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, false
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), true
+}
+
+// redactText replaces values of configured fields in text that may be cut, without decoding it
+// Covers JSON "field": value pairs and form-encoded field=value pairs
+//
+// redactText 在可能被截断的文本中替换配置字段的值，不进行解码
+// 覆盖 JSON 的 "field": value 键值对和表单编码的 field=value 键值对
+func redactText(cfg *Config, content []byte) []byte {
+	if len(cfg.RedactFields) == 0 {
+		return content
+	}
+	names := make([]string, 0, len(cfg.RedactFields))
+	for _, field := range cfg.RedactFields {
+		names = append(names, regexp.QuoteMeta(field))
+	}
+	fields := strings.Join(names, "|")
+	jsonPattern := regexp.MustCompile(`(?i)("(?:` + fields + `)"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	formPattern := regexp.MustCompile(`(?i)((?:^|[&;?\s])(?:` + fields + `)=)[^&;\s]*`)
+	content = jsonPattern.ReplaceAll(content, []byte(`${1}"`+redactedValue+`"`))
+	return formPattern.ReplaceAll(content, []byte(`${1}`+redactedValue))
+}
+
+// redactValue walks the decoded JSON and redacts matching fields in place
+// redactValue 遍历解码后的 JSON 并原地脱敏匹配的字段
+func redactValue(cfg *Config, value any) bool {
+	redacted := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isRedactField(cfg, key) {
+				v[key] = redactedValue
+				redacted = true
+			} else if redactValue(cfg, item) {
+				redacted = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactValue(cfg, item) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// isRedactField checks if the JSON field is configured as secret, ignoring case
+// isRedactField 检查 JSON 字段是否配置为敏感，不区分大小写
+func isRedactField(cfg *Config, name string) bool {
+	for _, field := range cfg.RedactFields {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateUTF8 cuts content to at most limit bytes without splitting a rune
+// truncateUTF8 将内容截断到最多 limit 字节，不会截断字符
+func truncateUTF8(content []byte, limit int) string {
+	if len(content) <= limit {
+		return string(content)
+	}
+	return fmt.Sprintf("%s...(%d bytes total)", content[:cutUTF8(content, limit)], len(content))
+}
+
+// cutUTF8 returns the length of the longest prefix within limit bytes not splitting a rune
+// cutUTF8 返回不超过 limit 字节且不截断字符的最长前缀长度
+func cutUTF8(content []byte, limit int) int {
+	if len(content) <= limit {
+		return len(content)
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return cut
+}
@@ -0,0 +1,104 @@
+package restyoops_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// detectBody serves the body with HTTP 500 and returns the detected Oops
+// detectBody 以 HTTP 500 返回响应体并返回检测得到的 Oops
+func detectBody(t *testing.T, cfg *restyoops.Config, contentType string, body []byte) *restyoops.Oops {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	resp, err := resty.New().R().Get(server.URL)
+	oops := restyoops.Detect(cfg, resp, err)
+	require.NotNil(t, oops)
+	return oops
+}
+
+// TestDetect_BodySnippet_Disabled tests no snippet is captured by default
+// TestDetect_BodySnippet_Disabled 测试默认不捕获响应体摘录
+func TestDetect_BodySnippet_Disabled(t *testing.T) {
+	oops := detectBody(t, restyoops.NewConfig(), "text/plain", []byte("database is down"))
+	require.Empty(t, oops.BodySnippet)
+}
+
+// TestDetect_BodySnippet_RedactJSON tests configured JSON fields are redacted at any depth
+// TestDetect_BodySnippet_RedactJSON 测试任意层级的配置 JSON 字段都会被脱敏
+func TestDetect_BodySnippet_RedactJSON(t *testing.T) {
+	cfg := restyoops.NewConfig().WithBodySnippet(256)
+	body := []byte(`{"msg":"login failed","user":{"name":"a<b>","Password":"hunter2"},"items":[{"token":"t1"}]}`)
+
+	oops := detectBody(t, cfg, "application/json", body)
+	require.Contains(t, oops.BodySnippet, `"msg":"login failed"`)
+	require.Contains(t, oops.BodySnippet, `"name":"a<b>"`)
+	require.Contains(t, oops.BodySnippet, `"Password":"REDACTED"`)
+	require.Contains(t, oops.BodySnippet, `"token":"REDACTED"`)
+	require.NotContains(t, oops.BodySnippet, "hunter2")
+	require.NotContains(t, oops.BodySnippet, "t1")
+}
+
+// TestDetect_BodySnippet_Truncate tests truncation never splits a multi-byte rune
+// TestDetect_BodySnippet_Truncate 测试截断不会拆分多字节字符
+func TestDetect_BodySnippet_Truncate(t *testing.T) {
+	cfg := restyoops.NewConfig().WithBodySnippet(8)
+	body := []byte("服务繁忙请稍后") // 3 bytes per rune
+
+	oops := detectBody(t, cfg, "text/plain; charset=utf-8", body)
+	require.Equal(t, "服务...(21 bytes total)", oops.BodySnippet)
+}
+
+// TestDetect_BodySnippet_Binary tests binary bodies are summarised by size
+// TestDetect_BodySnippet_Binary 测试二进制响应体仅显示大小
+func TestDetect_BodySnippet_Binary(t *testing.T) {
+	cfg := restyoops.NewConfig().WithBodySnippet(64)
+
+	oops := detectBody(t, cfg, "text/plain", []byte{0x89, 'P', 'N', 'G', 0x00, 0x01})
+	require.Equal(t, "<binary 6 bytes>", oops.BodySnippet)
+
+	oops = detectBody(t, cfg, "application/octet-stream", []byte("plain looking bytes"))
+	require.Equal(t, "<binary 19 bytes>", oops.BodySnippet)
+
+	oops = detectBody(t, cfg, "text/html", []byte(strings.Repeat("<p>", 10)))
+	require.Equal(t, strings.Repeat("<p>", 10), oops.BodySnippet)
+}
+
+// TestDetect_BodySnippet_LargeJSON tests large JSON bodies are redacted as text without decoding, even cut in a value
+// TestDetect_BodySnippet_LargeJSON 测试大型 JSON 响应体不解码而按文本脱敏，即使截断在值中间
+func TestDetect_BodySnippet_LargeJSON(t *testing.T) {
+	body := []byte(`{"msg":"failed","Token" : "t1","items":[` + strings.Repeat(`{"id":1},`, 10<<10) + `{"id":1}]}`)
+
+	oops := detectBody(t, restyoops.NewConfig().WithBodySnippet(40), "application/json", body)
+	require.True(t, strings.HasPrefix(oops.BodySnippet, `{"msg":"failed","Token" : "REDACTED","items":[`), oops.BodySnippet)
+	require.True(t, strings.HasSuffix(oops.BodySnippet, "bytes total)"), oops.BodySnippet)
+	require.NotContains(t, oops.BodySnippet, "t1")
+
+	oops = detectBody(t, restyoops.NewConfig().WithBodySnippet(28), "application/json", body)
+	require.Equal(t, `{"msg":"failed","Token" : "REDACTED"...(`+strconv.Itoa(len(body))+` bytes total)`, oops.BodySnippet)
+}
+
+// TestDetect_BodySnippet_RedactText tests small bodies that are not JSON are redacted as text
+// TestDetect_BodySnippet_RedactText 测试非 JSON 的小型响应体按文本脱敏
+func TestDetect_BodySnippet_RedactText(t *testing.T) {
+	cfg := restyoops.NewConfig().WithBodySnippet(256)
+
+	oops := detectBody(t, cfg, "application/x-www-form-urlencoded", []byte("user=alice&password=hunter2&token=t1"))
+	require.Equal(t, "user=alice&password=REDACTED&token=REDACTED", oops.BodySnippet)
+
+	oops = detectBody(t, cfg, "text/plain", []byte(`error: {"password":"hunter2"`))
+	require.Equal(t, `error: {"password":"REDACTED"`, oops.BodySnippet)
+
+	require.Equal(t, "password=REDACTED", string(restyoops.RedactBody(cfg, []byte("password=hunter2"))))
+}
@@ -0,0 +1,77 @@
+package restyoops
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker holds the pending failure and the attempt history of each request in flight, keyed by the request
+// Integrations use it to hold the failure of an attempt until the client decides whether to retry
+//
+// Tracker 按请求保存每个进行中请求的待处理失败和尝试历史
+// 集成使用它保存单次尝试的失败，直到客户端决定是否重试
+type Tracker[K comparable] struct {
+	mutex    sync.Mutex
+	pending  map[K]*Oops
+	attempts map[K][]*Attempt
+}
+
+// NewTracker creates an empty Tracker
+// NewTracker 创建空的 Tracker
+func NewTracker[K comparable]() *Tracker[K] {
+	return &Tracker[K]{
+		pending:  make(map[K]*Oops),
+		attempts: make(map[K][]*Attempt),
+	}
+}
+
+// SetPending keeps the failure of the current attempt until the client decides whether to retry, nil clears it
+// SetPending 保存当前尝试的失败，直到客户端决定是否重试，nil 表示清除
+func (t *Tracker[K]) SetPending(key K, oops *Oops) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if oops == nil {
+		delete(t.pending, key)
+		return
+	}
+	t.pending[key] = oops
+}
+
+// TakePending returns and clears the failure of the current attempt, nil when none
+// TakePending 返回并清除当前尝试的失败，没有时返回 nil
+func (t *Tracker[K]) TakePending(key K) *Oops {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	oops := t.pending[key]
+	delete(t.pending, key)
+	return oops
+}
+
+// AddAttempt appends the attempt to the history of the request
+// AddAttempt 将尝试追加到请求的历史中
+func (t *Tracker[K]) AddAttempt(key K, attempt *Attempt) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.attempts[key] = append(t.attempts[key], attempt)
+}
+
+// Finish marks the final failure as exhausted, attaching the history when retried
+// Finish 将最终失败标记为已耗尽，重试过时附带尝试历史
+func (t *Tracker[K]) Finish(key K, oops *Oops, duration time.Duration) *Oops {
+	t.mutex.Lock()
+	attempts := t.attempts[key]
+	t.mutex.Unlock()
+	if len(attempts) > 0 {
+		oops.WithAttempts(append(attempts, NewAttempt(oops, duration))...)
+	}
+	return oops.WithExhausted()
+}
+
+// Forget drops what is kept of the request once the client is done with it
+// Forget 在客户端处理完请求后丢弃为其保存的内容
+func (t *Tracker[K]) Forget(key K) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.pending, key)
+	delete(t.attempts, key)
+}
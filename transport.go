@@ -0,0 +1,203 @@
+package restyoops
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yyle88/must"
+)
+
+// DetectHTTP classifies a net/http response with the same rules as Detect
+// The body is read up to BodyLimit when checks or the snippet need it, so the caller can still read it
+//
+// DetectHTTP 使用与 Detect 相同的规则分类 net/http 响应
+// 检查或摘录需要时最多读取 BodyLimit 字节的响应体，调用方仍可读取
+func DetectHTTP(cfg *Config, resp *http.Response, respCause error) *Oops {
+	exchange, readCause := newHTTPExchange(cfg, resp)
+	if respCause == nil {
+		respCause = readCause
+	}
+	return DetectExchange(cfg, exchange, respCause)
+}
+
+// DetectHTTP classifies a net/http response and returns both response and oops issue
+// The Config is selected by the routes matching the request, falling back to the default one
+//
+// DetectHTTP 分类 net/http 响应并返回响应和 oops 问题
+// 根据匹配请求的路由选择 Config，未匹配时使用默认 Config
+func (c *Detective) DetectHTTP(resp *http.Response, respCause error) (*http.Response, *OopsIssue) {
+	var req *http.Request
+	if resp != nil {
+		req = resp.Request
+	}
+	oops := DetectHTTP(c.SelectHTTP(req), resp, respCause)
+	if oops != nil {
+		must.Nice(oops.Kind)
+		must.Wrong(oops.Cause)
+	}
+	return resp, oops
+}
+
+// Transport is an http.RoundTripper classifying each response with the Detective, retrying when configured
+// Hooks receive the final failure marked as exhausted, carrying the attempt history when retried
+//
+// Transport 是使用 Detective 分类每个响应的 http.RoundTripper，配置后可自动重试
+// 钩子接收标记为已耗尽的最终失败，重试过时附带尝试历史
+type Transport struct {
+	base          http.RoundTripper
+	detective     *Detective
+	maxRetries    int
+	nonIdempotent bool
+	hooks         []Hook
+}
+
+// NewTransport creates a Transport over http.DefaultTransport, without retries
+// NewTransport 创建基于 http.DefaultTransport 的 Transport，不重试
+func NewTransport(detective *Detective) *Transport {
+	return &Transport{
+		base:          http.DefaultTransport,
+		detective:     must.Full(detective),
+		maxRetries:    0,
+		nonIdempotent: false,
+		hooks:         nil,
+	}
+}
+
+// WithBase sets the underlying RoundTripper
+// WithBase 设置底层的 RoundTripper
+func (t *Transport) WithBase(base http.RoundTripper) *Transport {
+	must.True(base != nil)
+	t.base = base
+	return t
+}
+
+// WithRetries sets the max retries of retryable failures, waiting the suggested WaitTime in between
+// WithRetries 设置可重试失败的最大重试次数，期间等待建议的等待时间
+func (t *Transport) WithRetries(maxRetries int) *Transport {
+	t.maxRetries = maxRetries
+	return t
+}
+
+// WithNonIdempotentRetries allows retrying non-idempotent methods like POST and PATCH
+// Without it only idempotent methods and requests with an Idempotency-Key header are retried
+//
+// WithNonIdempotentRetries 允许重试 POST 和 PATCH 等非幂等方法
+// 未设置时仅重试幂等方法和带有 Idempotency-Key 头的请求
+func (t *Transport) WithNonIdempotentRetries() *Transport {
+	t.nonIdempotent = true
+	return t
+}
+
+// WithHooks appends the hooks receiving the final failure
+// WithHooks 追加接收最终失败的钩子
+func (t *Transport) WithHooks(hooks ...Hook) *Transport {
+	t.hooks = append(t.hooks, hooks...)
+	return t
+}
+
+// RoundTrip sends the request, classifying and retrying until success, a final failure or retries used up
+// Requests with a body are retried only when GetBody is set, like those built via http.NewRequest
+// Non-idempotent requests are retried only with WithNonIdempotentRetries
+// The last failure reaches the hooks also when the context ends during the wait before a retry
+//
+// RoundTrip 发送请求，分类并重试，直到成功、最终失败或重试次数用尽
+// 带请求体的请求仅在设置了 GetBody 时重试，例如通过 http.NewRequest 构建的请求
+// 非幂等请求仅在设置 WithNonIdempotentRetries 后重试
+// 重试前等待期间上下文结束时，最后一次失败同样会传递给钩子
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg := t.detective.SelectHTTP(req)
+	var attempts []*Attempt
+	for attempt := 0; ; attempt++ {
+		tryReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		startTime := time.Now()
+		resp, respCause := t.base.RoundTrip(tryReq)
+		exchange, readCause := newHTTPExchange(cfg, resp)
+		duration := time.Since(startTime)
+		if exchange != nil {
+			exchange.Duration = duration
+		}
+		cause := respCause
+		if cause == nil {
+			cause = readCause
+		}
+
+		oops := DetectExchange(cfg, exchange, cause)
+		if oops == nil {
+			return resp, nil
+		}
+		attempts = append(attempts, NewAttempt(oops, duration))
+		if !oops.Retryable || attempt >= t.maxRetries || !t.canRetry(req) {
+			if oops.Retryable {
+				oops.WithExhausted() // retries used up, or the request cannot be sent again
+			}
+			t.runHooks(req, oops, attempts)
+			return resp, respCause
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		timer := time.NewTimer(oops.WaitTime)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			t.runHooks(req, oops, attempts)
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// runHooks passes the last failure to the hooks, with the attempt history when retried
+// runHooks 将最后一次失败传递给钩子，重试过时附带尝试历史
+func (t *Transport) runHooks(req *http.Request, oops *Oops, attempts []*Attempt) {
+	if len(attempts) > 1 {
+		oops.WithAttempts(attempts...)
+	}
+	RunHooks(req.Context(), oops, t.hooks)
+}
+
+// rewindRequest returns the request of the attempt, with a fresh body on retries
+// rewindRequest 返回本次尝试的请求，重试时使用新的请求体
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	res := req.Clone(req.Context())
+	res.Body = body
+	return res, nil
+}
+
+// canRetry checks if the request may be sent again, by its method and body
+// canRetry 根据请求方法和请求体检查请求是否可以再次发送
+func (t *Transport) canRetry(req *http.Request) bool {
+	return (t.nonIdempotent || isIdempotent(req)) && canRewind(req)
+}
+
+// isIdempotent checks if the request is idempotent, following the rules of net/http
+// isIdempotent 按照 net/http 的规则检查请求是否幂等
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, hasKey := req.Header["Idempotency-Key"]
+	_, hasXKey := req.Header["X-Idempotency-Key"]
+	return hasKey || hasXKey
+}
+
+// canRewind checks if the request body can be sent again
+// canRewind 检查请求体是否可以再次发送
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
@@ -0,0 +1,259 @@
+package restyoops_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/restyoops"
+)
+
+// TestDetectHTTP_MatchesResty tests net/http and resty responses are classified identically
+// TestDetectHTTP_MatchesResty 测试 net/http 和 resty 响应的分类结果一致
+func TestDetectHTTP_MatchesResty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/business":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"code":1001}`))
+		case "/busy":
+			w.Header().Set("X-Request-Id", "req-1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("busy"))
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	cfg := restyoops.NewConfig().
+		WithBodySnippet(64).
+		WithContentCheck(200, func(contentType string, content []byte) *restyoops.Oops {
+			if strings.Contains(string(content), `"code":1001`) {
+				return restyoops.NewOops(restyoops.KindBusiness, 200, errors.New("code 1001"), false)
+			}
+			return nil
+		})
+
+	for _, path := range []string{"/business", "/busy", "/ok"} {
+		restyResp, err := resty.New().R().Get(server.URL + path)
+		require.NoError(t, err)
+		expected := restyoops.Detect(cfg, restyResp, nil)
+
+		httpResp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		oops := restyoops.DetectHTTP(cfg, httpResp, nil)
+
+		// Body stays readable after detection
+		content, err := io.ReadAll(httpResp.Body)
+		require.NoError(t, err)
+		require.NoError(t, httpResp.Body.Close())
+		require.Equal(t, restyResp.String(), string(content))
+
+		if expected == nil {
+			require.Nil(t, oops)
+			continue
+		}
+		require.Equal(t, expected.Kind, oops.Kind)
+		require.Equal(t, expected.StatusCode, oops.StatusCode)
+		require.Equal(t, expected.Retryable, oops.Retryable)
+		require.Equal(t, expected.Reason, oops.Reason)
+		require.Equal(t, expected.URL, oops.URL)
+		require.Equal(t, expected.RequestID, oops.RequestID)
+		require.Equal(t, expected.ResponseRequestID, oops.ResponseRequestID)
+		require.Equal(t, expected.BodySnippet, oops.BodySnippet)
+	}
+}
+
+// TestDetectHTTP_NetworkCause tests transport failures are classified without a response
+// TestDetectHTTP_NetworkCause 测试没有响应时的传输失败分类
+func TestDetectHTTP_NetworkCause(t *testing.T) {
+	oops := restyoops.DetectHTTP(restyoops.NewConfig(), nil, context.DeadlineExceeded)
+	require.Equal(t, restyoops.KindNetwork, oops.Kind)
+	require.Equal(t, restyoops.ReasonTimeout, oops.Reason)
+	require.True(t, oops.Retryable)
+}
+
+// TestTransport_Retries tests the Transport retries retryable failures and replays the request body
+// TestTransport_Retries 测试 Transport 重试可重试的失败并重放请求体
+func TestTransport_Retries(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		require.Equal(t, "payload", string(content))
+		if count.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	detective := restyoops.NewDetective(restyoops.NewConfig().WithDefaultWait(time.Millisecond))
+	var hooked []*restyoops.Oops
+	transport := restyoops.NewTransport(detective).
+		WithRetries(2).
+		WithNonIdempotentRetries().
+		WithHooks(func(ctx context.Context, oops *restyoops.Oops) {
+			hooked = append(hooked, oops)
+		})
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(3), count.Load())
+	require.Empty(t, hooked)
+}
+
+// TestTransport_GivesUp tests the final failure reaches the hooks with its attempt history
+// TestTransport_GivesUp 测试最终失败携带尝试历史传递给钩子
+func TestTransport_GivesUp(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	}))
+	defer server.Close()
+
+	detective := restyoops.NewDetective(restyoops.NewConfig().WithDefaultWait(time.Millisecond))
+	var hooked []*restyoops.Oops
+	transport := restyoops.NewTransport(detective).
+		WithRetries(5).
+		WithHooks(func(ctx context.Context, oops *restyoops.Oops) {
+			hooked = append(hooked, oops)
+		})
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "missing", string(content))
+
+	require.Equal(t, int32(2), count.Load()) // 404 is not retryable
+	require.Len(t, hooked, 1)
+	require.Equal(t, http.StatusNotFound, hooked[0].StatusCode)
+	require.Equal(t, "2 attempts: 503, 404", hooked[0].Summary())
+	require.Equal(t, http.MethodGet, hooked[0].Method)
+	require.False(t, hooked[0].Exhausted) // not retryable, no retries ran out
+}
+
+// TestTransport_Cancelled tests the last failure reaches the hooks when the context ends during the wait
+// TestTransport_Cancelled 测试等待期间上下文结束时最后一次失败传递给钩子
+func TestTransport_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var count int
+	cfg := restyoops.NewConfig().WithHeaderCheck(http.StatusServiceUnavailable, func(header http.Header, content []byte) *restyoops.Oops {
+		oops := restyoops.NewOops(restyoops.KindHttp, http.StatusServiceUnavailable, errors.New("unavailable"), true)
+		if count++; count == 1 {
+			return oops.WithWaitTime(time.Millisecond)
+		}
+		cancel() // the context ends during the wait before the third attempt
+		return oops.WithWaitTime(time.Hour)
+	})
+	var hooked []*restyoops.Oops
+	transport := restyoops.NewTransport(restyoops.NewDetective(cfg)).
+		WithRetries(5).
+		WithHooks(func(ctx context.Context, oops *restyoops.Oops) {
+			hooked = append(hooked, oops)
+		})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 2, count)
+	require.Len(t, hooked, 1)
+	require.Equal(t, http.StatusServiceUnavailable, hooked[0].StatusCode)
+	require.Len(t, hooked[0].Attempts, 2)
+	require.False(t, hooked[0].Exhausted)
+}
+
+// TestTransport_NonIdempotent tests POST is sent once by default, and retried with an Idempotency-Key header
+// TestTransport_NonIdempotent 测试 POST 默认只发送一次，带有 Idempotency-Key 头时会重试
+func TestTransport_NonIdempotent(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	detective := restyoops.NewDetective(restyoops.NewConfig().WithDefaultWait(time.Millisecond))
+	var hooked []*restyoops.Oops
+	transport := restyoops.NewTransport(detective).
+		WithRetries(2).
+		WithHooks(func(ctx context.Context, oops *restyoops.Oops) {
+			hooked = append(hooked, oops)
+		})
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, int32(1), count.Load())
+	require.Len(t, hooked, 1)
+	require.True(t, hooked[0].Exhausted)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	req.Header.Set("Idempotency-Key", "order-1")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, int32(4), count.Load())
+}
+
+// TestDetectHTTP_BodyLimit tests the body is read only when needed, capped, and still fully readable
+// TestDetectHTTP_BodyLimit 测试仅在需要时读取响应体，读取有上限，且仍可完整读取
+func TestDetectHTTP_BodyLimit(t *testing.T) {
+	newResponse := func() *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader(strings.Repeat("x", 100))),
+		}
+	}
+
+	resp := newResponse()
+	body := resp.Body
+	oops := restyoops.DetectHTTP(restyoops.NewConfig(), resp, nil)
+	require.Equal(t, http.StatusInternalServerError, oops.StatusCode)
+	require.Equal(t, body, resp.Body) // no checks and no snippet, body untouched
+
+	var checked []byte
+	cfg := restyoops.NewConfig().
+		WithBodyLimit(10).
+		WithContentCheck(http.StatusInternalServerError, func(contentType string, content []byte) *restyoops.Oops {
+			checked = content
+			return nil
+		})
+	resp = newResponse()
+	restyoops.DetectHTTP(cfg, resp, nil)
+	require.Equal(t, strings.Repeat("x", 10), string(checked))
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, strings.Repeat("x", 100), string(content))
+}